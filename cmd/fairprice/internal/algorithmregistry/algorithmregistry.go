@@ -0,0 +1,71 @@
+package algorithmregistry
+
+import (
+	"fmt"
+	"sort"
+
+	"tickerprice/cmd/fairprice/internal/averagealgorithm"
+	"tickerprice/cmd/fairprice/internal/medianalgorithm"
+	"tickerprice/cmd/fairprice/internal/quotealgorithm"
+	"tickerprice/cmd/fairprice/internal/reliabilityalgorithm"
+	"tickerprice/cmd/fairprice/internal/trimmedalgorithm"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/cmd/fairprice/internal/vwapalgorithm"
+)
+
+// Config selects a price algorithm by name.
+type Config struct {
+	Name         string  // name of the algorithm. example: "average", "trimmed_mean"
+	TrimFraction float64 // part of prices trimmed at each end by "trimmed_mean" and "winsorized_mean"
+}
+
+var constructors = map[string]func(config Config) types.PriceAlgorithm{
+	"average": func(_ Config) types.PriceAlgorithm {
+		return averagealgorithm.New()
+	},
+	"median": func(_ Config) types.PriceAlgorithm {
+		return medianalgorithm.New()
+	},
+	"vwap": func(_ Config) types.PriceAlgorithm {
+		return vwapalgorithm.New()
+	},
+	"reliability": func(_ Config) types.PriceAlgorithm {
+		return reliabilityalgorithm.New(reliabilityalgorithm.DefaultConfig)
+	},
+	"spread_weighted": func(_ Config) types.PriceAlgorithm {
+		return quotealgorithm.NewSpreadWeighted()
+	},
+	"trimmed_mean": func(config Config) types.PriceAlgorithm {
+		return trimmedalgorithm.NewTrimmedMean(config.TrimFraction)
+	},
+	"winsorized_mean": func(config Config) types.PriceAlgorithm {
+		return trimmedalgorithm.NewWinsorizedMean(config.TrimFraction)
+	},
+}
+
+// New creates the price algorithm selected by the config.
+func New(config Config) (types.PriceAlgorithm, error) {
+	constructor, ok := constructors[config.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q, available: %v", config.Name, Names())
+	}
+
+	if config.TrimFraction < 0 || config.TrimFraction >= 0.5 {
+		return nil, fmt.Errorf("invalid trim fraction %v", config.TrimFraction)
+	}
+
+	return constructor(config), nil
+}
+
+// Names returns names of all algorithms in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(constructors))
+
+	for name := range constructors {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,34 @@
+package algorithmregistry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/algorithmregistry"
+	"tickerprice/cmd/fairprice/internal/trimmedalgorithm"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range algorithmregistry.Names() {
+		algorithm, err := algorithmregistry.New(algorithmregistry.Config{Name: name, TrimFraction: 0.1})
+
+		if assert.NoError(t, err, name) {
+			assert.NotNil(t, algorithm, name)
+		}
+	}
+
+	algorithm, err := algorithmregistry.New(algorithmregistry.Config{Name: "winsorized_mean", TrimFraction: 0.2})
+
+	if assert.NoError(t, err) {
+		assert.IsType(t, &trimmedalgorithm.WinsorizedMeanAlgorithm{}, algorithm)
+	}
+
+	_, err = algorithmregistry.New(algorithmregistry.Config{Name: "mode"})
+
+	assert.Error(t, err)
+
+	_, err = algorithmregistry.New(algorithmregistry.Config{Name: "trimmed_mean", TrimFraction: 0.5})
+
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
 )
 
 type AverageAlgorithm struct{}
@@ -14,16 +15,16 @@ func New() *AverageAlgorithm {
 }
 
 // CalculatePrice calculates an average price based on prices from different sources.
-func (c *AverageAlgorithm) CalculatePrice(prices map[types.SourceID]float64) (float64, error) {
+func (c *AverageAlgorithm) CalculatePrice(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
 	if len(prices) == 0 {
-		return 0, fmt.Errorf("not enough data to calculate an average price")
+		return decimal.Zero, fmt.Errorf("not enough data to calculate an average price")
 	}
 
-	var avg float64
+	var sum decimal.Decimal
 
 	for _, price := range prices {
-		avg += price
+		sum = sum.Add(price.Price)
 	}
 
-	return avg / float64(len(prices)), nil
+	return sum.Div(decimal.NewFromInt(int64(len(prices)))), nil
 }
@@ -1,36 +1,36 @@
 package averagealgorithm_test
 
 import (
-	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"tickerprice/cmd/fairprice/internal/averagealgorithm"
 	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
 )
 
 func TestAverageAlgorithm_CalculatePrice(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		mockPrices := map[types.SourceID]float64{
-			"a": 1.0,
-			"b": 2.0,
-			"c": 6.0,
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("1.0")},
+			"b": {Price: decimal.MustParse("2.0")},
+			"c": {Price: decimal.MustParse("6.0")},
 		}
 
-		expectedAveragePrice := 3.0
+		expectedAveragePrice := "3"
 
 		algorithm := averagealgorithm.New()
 
 		fairPrice, err := algorithm.CalculatePrice(mockPrices)
 
 		if assert.NoError(t, err) {
-			assert.Equal(t, expectedAveragePrice, math.Round(fairPrice))
+			assert.Equal(t, expectedAveragePrice, fairPrice.String())
 		}
 	})
 
 	t.Run("empty prices", func(t *testing.T) {
-		mockPrices := map[types.SourceID]float64{}
+		mockPrices := map[types.SourceID]types.SourcePrice{}
 
 		algorithm := averagealgorithm.New()
 
@@ -0,0 +1,320 @@
+package crossrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"tickerprice/cmd/fairprice/internal/errorstream"
+	"tickerprice/cmd/fairprice/internal/tickerregistry"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+//go:generate moq -pkg crossrate_test -out mocks_types_test.go ../types PriceStreamSubscriber
+
+// defaultMaxPending is the default number of incomplete timeslots kept while waiting for prices of the legs.
+const defaultMaxPending = 3600
+
+// DerivedPrice is a price of a derived ticker with the prices of the legs it is built from.
+type DerivedPrice struct {
+	Price types.TickerPrice
+	Legs  []LegPrice // in the order of the legs of the path
+}
+
+// LegPrice is a price of a leg used to build a derived price.
+type LegPrice struct {
+	Leg   Leg
+	Price types.TickerPrice
+}
+
+// CrossRateSource is the source of prices of tickers triangulated from per-timeslot prices of other tickers.
+// Prices of the legs are matched by time, so the upstream is expected to publish a single price per timeslot
+// like FairPriceSource does. The source can be used as a PriceStreamSubscriber of another FairPriceSource
+// to complement sources quoting the derived ticker directly.
+type CrossRateSource struct {
+	upstream     types.PriceStreamSubscriber
+	paths        map[types.Ticker]Path
+	registry     *tickerregistry.Registry
+	roundingMode decimal.RoundingMode
+	maxPending   int
+}
+
+// New creates a new initialized instance of CrossRateSource.
+// Paths must be valid and describe different tickers, the max pending limit must be positive.
+func New(upstream types.PriceStreamSubscriber, paths []Path, options ...Option) *CrossRateSource {
+	s := &CrossRateSource{
+		upstream:   upstream,
+		paths:      make(map[types.Ticker]Path, len(paths)),
+		registry:   tickerregistry.New(),
+		maxPending: defaultMaxPending,
+	}
+
+	for _, path := range paths {
+		if err := path.Validate(); err != nil {
+			panic(fmt.Sprintf("crossrate: invalid path: %v", err))
+		}
+
+		if _, ok := s.paths[path.Ticker]; ok {
+			panic(fmt.Sprintf("crossrate: duplicate path of ticker %s", path.Ticker))
+		}
+
+		s.paths[path.Ticker] = path
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	if s.maxPending <= 0 {
+		panic(fmt.Sprintf("crossrate: invalid max pending %d", s.maxPending))
+	}
+
+	return s
+}
+
+// SubscribePriceStream subscribes to prices of the derived ticker.
+func (s *CrossRateSource) SubscribePriceStream(
+	ctx context.Context,
+	ticker types.Ticker,
+) (<-chan types.TickerPrice, <-chan error) {
+	return subscribe(ctx, s, ticker, func(price DerivedPrice) types.TickerPrice {
+		return price.Price
+	})
+}
+
+// SubscribeDerivedStream subscribes to prices of the derived ticker with the prices of their legs.
+func (s *CrossRateSource) SubscribeDerivedStream(
+	ctx context.Context,
+	ticker types.Ticker,
+) (<-chan DerivedPrice, <-chan error) {
+	return subscribe(ctx, s, ticker, func(price DerivedPrice) DerivedPrice {
+		return price
+	})
+}
+
+// legTick is a price delivered by the subscription to a leg.
+type legTick struct {
+	index int
+	price types.TickerPrice
+}
+
+func subscribe[T any](
+	ctx context.Context,
+	s *CrossRateSource,
+	ticker types.Ticker,
+	convert func(price DerivedPrice) T,
+) (<-chan T, <-chan error) {
+	outPrices := make(chan T)
+	outErrors := make(chan error, errorstream.BufferSize)
+
+	path, ok := s.paths[ticker]
+	if !ok {
+		outErrors <- fmt.Errorf("no path of ticker %s", ticker)
+
+		close(outPrices)
+		close(outErrors)
+
+		return outPrices, outErrors
+	}
+
+	legTicks := make(chan legTick)
+
+	legsWaitGroup := sync.WaitGroup{}
+
+	for index, leg := range path.Legs {
+		legsWaitGroup.Add(1)
+
+		go func(index int, leg Leg) {
+			defer legsWaitGroup.Done()
+
+			s.runLeg(ctx, index, leg, legTicks, outErrors)
+		}(index, leg)
+	}
+
+	go func() {
+		legsWaitGroup.Wait()
+
+		close(legTicks)
+	}()
+
+	go func() {
+		defer close(outErrors)
+		defer close(outPrices)
+
+		s.runCombiner(ctx, path, legTicks, outErrors, func(price DerivedPrice) {
+			select {
+			case <-ctx.Done():
+			case outPrices <- convert(price):
+			}
+		})
+	}()
+
+	return outPrices, outErrors
+}
+
+func (s *CrossRateSource) runLeg(
+	ctx context.Context,
+	index int,
+	leg Leg,
+	legTicks chan<- legTick,
+	outErrors chan<- error,
+) {
+	tickerPrices, tickerErrors := s.upstream.SubscribePriceStream(ctx, leg.Ticker)
+
+	for tickerPrices != nil || tickerErrors != nil {
+		select {
+		case tickerPrice, ok := <-tickerPrices:
+			if !ok {
+				tickerPrices = nil
+				continue
+			}
+
+			legTicks <- legTick{index: index, price: tickerPrice}
+
+		case tickerError, ok := <-tickerErrors:
+			if !ok {
+				tickerErrors = nil
+				continue
+			}
+
+			errorstream.Report(ctx, outErrors, fmt.Errorf("leg %s: %w", leg.Ticker, tickerError))
+		}
+	}
+}
+
+// runCombiner matches prices of the legs by time and publishes a derived price once every leg has delivered.
+func (s *CrossRateSource) runCombiner(
+	ctx context.Context,
+	path Path,
+	legTicks <-chan legTick,
+	outErrors chan<- error,
+	publish func(price DerivedPrice),
+) {
+	pending := make(map[types.Timeslot][]*types.TickerPrice)
+
+	// the latest timeslot delivered by every leg
+	latest := make([]types.Timeslot, len(path.Legs))
+
+	for tick := range legTicks {
+		timeslot := types.Timeslot(tick.price.Time.Unix())
+
+		latest[tick.index] = timeslot
+
+		legs, ok := pending[timeslot]
+		if !ok {
+			legs = make([]*types.TickerPrice, len(path.Legs))
+			pending[timeslot] = legs
+		}
+
+		price := tick.price
+		legs[tick.index] = &price
+
+		if !complete(legs) {
+			s.prunePending(pending, latest)
+			continue
+		}
+
+		// every leg delivers in time order, so earlier incomplete timeslots can never be completed
+		for pendingTimeslot := range pending {
+			if pendingTimeslot <= timeslot {
+				delete(pending, pendingTimeslot)
+			}
+		}
+
+		derived, err := s.derive(path, legs)
+		if err != nil {
+			errorstream.Report(ctx, outErrors, fmt.Errorf("derive ticker %s at %v: %w", path.Ticker, tick.price.Time, err))
+			continue
+		}
+
+		publish(derived)
+	}
+}
+
+// derive multiplies prices of the legs. The status of the derived price is the first degraded status of the legs.
+func (s *CrossRateSource) derive(path Path, legs []*types.TickerPrice) (DerivedPrice, error) {
+	product := decimal.NewFromInt(1)
+	status := types.PriceStatusOK
+
+	legPrices := make([]LegPrice, 0, len(legs))
+
+	for index, leg := range path.Legs {
+		legPrice := *legs[index]
+
+		price, err := decimal.Parse(legPrice.Price)
+		if err != nil {
+			return DerivedPrice{}, fmt.Errorf("leg %s: %w", leg.Ticker, err)
+		}
+
+		if leg.Invert {
+			if price.IsZero() {
+				return DerivedPrice{}, fmt.Errorf("leg %s: zero price cannot be inverted", leg.Ticker)
+			}
+
+			price = decimal.NewFromInt(1).Div(price)
+		}
+
+		product = product.Mul(price)
+
+		if status == types.PriceStatusOK {
+			status = legPrice.Status
+		}
+
+		legPrices = append(legPrices, LegPrice{Leg: leg, Price: legPrice})
+	}
+
+	return DerivedPrice{
+		Price: types.TickerPrice{
+			Ticker: path.Ticker,
+			Time:   legs[0].Time,
+			Price:  s.registry.FormatPrice(path.Ticker, product, s.roundingMode),
+			Status: status,
+		},
+		Legs: legPrices,
+	}, nil
+}
+
+// prunePending drops timeslots which cannot be completed anymore because every leg has delivered a later price.
+// If a leg stops delivering, the oldest timeslots beyond maxPending are dropped.
+func (s *CrossRateSource) prunePending(pending map[types.Timeslot][]*types.TickerPrice, latest []types.Timeslot) {
+	oldest := latest[0]
+
+	for _, timeslot := range latest[1:] {
+		if timeslot < oldest {
+			oldest = timeslot
+		}
+	}
+
+	timeslots := make([]types.Timeslot, 0, len(pending))
+
+	for timeslot := range pending {
+		if timeslot < oldest {
+			delete(pending, timeslot)
+			continue
+		}
+
+		timeslots = append(timeslots, timeslot)
+	}
+
+	if len(timeslots) <= s.maxPending {
+		return
+	}
+
+	sort.Slice(timeslots, func(i, j int) bool { return timeslots[i] < timeslots[j] })
+
+	for _, timeslot := range timeslots[:len(timeslots)-s.maxPending] {
+		delete(pending, timeslot)
+	}
+}
+
+func complete(legs []*types.TickerPrice) bool {
+	for _, leg := range legs {
+		if leg == nil {
+			return false
+		}
+	}
+
+	return true
+}
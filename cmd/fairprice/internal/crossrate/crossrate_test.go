@@ -0,0 +1,197 @@
+package crossrate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/crossrate"
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+func TestCrossRateSource_SubscribeDerivedStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ETH_EUR")
+
+		mockLegPrices = map[types.Ticker][]types.TickerPrice{
+			"ETH_USD": {
+				{Ticker: "ETH_USD", Time: time.Unix(60, 0), Price: "3000"},
+				{Ticker: "ETH_USD", Time: time.Unix(120, 0), Price: "2400", Status: types.PriceStatusStale},
+			},
+			// the first timeslot of the leg is missing, it cannot be derived
+			"EUR_USD": {
+				{Ticker: "EUR_USD", Time: time.Unix(120, 0), Price: "1.2"},
+			},
+		}
+
+		mockUpstream = &PriceStreamSubscriberMock{
+			SubscribePriceStreamFunc: func(
+				ctx context.Context,
+				ticker types.Ticker,
+			) (
+				<-chan types.TickerPrice,
+				<-chan error,
+			) {
+				tickers := make(chan types.TickerPrice, len(mockLegPrices[ticker]))
+				errors := make(chan error)
+
+				go func() {
+					<-ctx.Done()
+					close(tickers)
+					close(errors)
+				}()
+
+				for _, price := range mockLegPrices[ticker] {
+					tickers <- price
+				}
+
+				return tickers, errors
+			},
+		}
+	)
+
+	source := crossrate.New(mockUpstream, []crossrate.Path{
+		{
+			Ticker: mockTicker,
+			Legs: []crossrate.Leg{
+				{Ticker: "ETH_USD"},
+				{Ticker: "EUR_USD", Invert: true},
+			},
+		},
+	})
+
+	derivedPrices, _ := source.SubscribeDerivedStream(ctx, mockTicker)
+
+	var resultPrices []crossrate.DerivedPrice
+
+	for price := range derivedPrices {
+		resultPrices = append(resultPrices, price)
+
+		cancel()
+	}
+
+	if assert.Equal(t, 1, len(resultPrices)) {
+		assert.Equal(t, types.TickerPrice{
+			Ticker: mockTicker,
+			Time:   time.Unix(120, 0),
+			Price:  "2000",
+			Status: types.PriceStatusStale,
+		}, resultPrices[0].Price)
+
+		assert.Equal(t, []crossrate.LegPrice{
+			{Leg: crossrate.Leg{Ticker: "ETH_USD"}, Price: mockLegPrices["ETH_USD"][1]},
+			{Leg: crossrate.Leg{Ticker: "EUR_USD", Invert: true}, Price: mockLegPrices["EUR_USD"][0]},
+		}, resultPrices[0].Legs)
+	}
+}
+
+func TestCrossRateSource_SubscribePriceStream_MaxPending(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ETH_EUR")
+
+		mockLegPrices = map[types.Ticker][]types.TickerPrice{
+			"ETH_USD": {
+				{Ticker: "ETH_USD", Time: time.Unix(60, 0), Price: "3000"},
+				{Ticker: "ETH_USD", Time: time.Unix(120, 0), Price: "2400"},
+				{Ticker: "ETH_USD", Time: time.Unix(180, 0), Price: "3600"},
+			},
+			// the leg is behind, its first timeslot has been dropped by the time it is delivered
+			"EUR_USD": {
+				{Ticker: "EUR_USD", Time: time.Unix(60, 0), Price: "1.2"},
+				{Ticker: "EUR_USD", Time: time.Unix(180, 0), Price: "1.2"},
+			},
+		}
+
+		mockLegDelays = map[types.Ticker]time.Duration{
+			"EUR_USD": 100 * time.Millisecond,
+		}
+
+		mockUpstream = &PriceStreamSubscriberMock{
+			SubscribePriceStreamFunc: func(
+				ctx context.Context,
+				ticker types.Ticker,
+			) (
+				<-chan types.TickerPrice,
+				<-chan error,
+			) {
+				tickers := make(chan types.TickerPrice, len(mockLegPrices[ticker]))
+				errors := make(chan error)
+
+				go func() {
+					time.Sleep(mockLegDelays[ticker])
+
+					for _, price := range mockLegPrices[ticker] {
+						tickers <- price
+					}
+
+					<-ctx.Done()
+					close(tickers)
+					close(errors)
+				}()
+
+				return tickers, errors
+			},
+		}
+	)
+
+	source := crossrate.New(mockUpstream, []crossrate.Path{
+		{
+			Ticker: mockTicker,
+			Legs: []crossrate.Leg{
+				{Ticker: "ETH_USD"},
+				{Ticker: "EUR_USD", Invert: true},
+			},
+		},
+	}, crossrate.WithMaxPending(2))
+
+	tickerPrices, _ := source.SubscribePriceStream(ctx, mockTicker)
+
+	var resultPrices []types.TickerPrice
+
+	for price := range tickerPrices {
+		resultPrices = append(resultPrices, price)
+
+		if price.Time.Equal(time.Unix(180, 0)) {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, []types.TickerPrice{
+		{Ticker: mockTicker, Time: time.Unix(180, 0), Price: "3000"},
+	}, resultPrices)
+}
+
+func TestNew_InvalidMaxPending(t *testing.T) {
+	for _, limit := range []int{0, -1} {
+		assert.Panics(t, func() { crossrate.New(&PriceStreamSubscriberMock{}, nil, crossrate.WithMaxPending(limit)) })
+	}
+}
+
+func TestCrossRateSource_SubscribePriceStream_UnknownTicker(t *testing.T) {
+	source := crossrate.New(&PriceStreamSubscriberMock{}, nil)
+
+	tickerPrices, tickerErrors := source.SubscribePriceStream(context.Background(), "ETH_EUR")
+
+	_, ok := <-tickerPrices
+
+	assert.False(t, ok)
+	assert.Error(t, <-tickerErrors)
+}
+
+func TestPath_Validate(t *testing.T) {
+	assert.NoError(t, crossrate.Path{Ticker: "ETH_EUR", Legs: []crossrate.Leg{{Ticker: "ETH_USD"}}}.Validate())
+	assert.Error(t, crossrate.Path{Ticker: "ETH_EUR"}.Validate())
+	assert.Error(t, crossrate.Path{Ticker: "ETH_EUR", Legs: []crossrate.Leg{{Ticker: "ETH_EUR"}}}.Validate())
+	assert.Error(t, crossrate.Path{
+		Ticker: "ETH_EUR",
+		Legs:   []crossrate.Leg{{Ticker: "ETH_USD"}, {Ticker: "ETH_USD"}},
+	}.Validate())
+}
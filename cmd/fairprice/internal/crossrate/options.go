@@ -0,0 +1,33 @@
+package crossrate
+
+import (
+	"tickerprice/cmd/fairprice/internal/tickerregistry"
+	"tickerprice/internal/decimal"
+)
+
+// Option configures an optional behaviour of CrossRateSource.
+type Option func(*CrossRateSource)
+
+// WithTickerRegistry sets the metadata of derived tickers used to format their prices.
+// By default prices are rounded to 10 digits without trailing zeros.
+func WithTickerRegistry(registry *tickerregistry.Registry) Option {
+	return func(s *CrossRateSource) {
+		s.registry = registry
+	}
+}
+
+// WithMaxPending sets how many incomplete timeslots are kept while waiting for prices of the legs.
+// The oldest ones are dropped when a leg stops delivering. The default is 3600, the limit must be positive.
+func WithMaxPending(limit int) Option {
+	return func(s *CrossRateSource) {
+		s.maxPending = limit
+	}
+}
+
+// WithRoundingMode sets how derived prices are rounded to the tick size and precision of the ticker.
+// By default prices are rounded half away from zero.
+func WithRoundingMode(mode decimal.RoundingMode) Option {
+	return func(s *CrossRateSource) {
+		s.roundingMode = mode
+	}
+}
@@ -0,0 +1,48 @@
+package crossrate
+
+import (
+	"fmt"
+
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+// Leg is a ticker the price of a derived ticker is built from.
+type Leg struct {
+	Ticker types.Ticker
+	Invert bool // use the reciprocal of the price. example: EUR_USD for the USD_EUR leg
+}
+
+// Path describes how a derived ticker is triangulated from its legs.
+// The price of the derived ticker is the product of the prices of the legs.
+// example: ETH_EUR is ETH_USD and inverted EUR_USD.
+type Path struct {
+	Ticker types.Ticker
+	Legs   []Leg
+}
+
+// Validate checks the path can be triangulated.
+func (p Path) Validate() error {
+	if p.Ticker == "" {
+		return fmt.Errorf("empty ticker")
+	}
+
+	if len(p.Legs) == 0 {
+		return fmt.Errorf("ticker %s: no legs", p.Ticker)
+	}
+
+	seen := make(map[types.Ticker]struct{}, len(p.Legs))
+
+	for _, leg := range p.Legs {
+		if leg.Ticker == p.Ticker {
+			return fmt.Errorf("ticker %s: derived from itself", p.Ticker)
+		}
+
+		if _, ok := seen[leg.Ticker]; ok {
+			return fmt.Errorf("ticker %s: duplicate leg %s", p.Ticker, leg.Ticker)
+		}
+
+		seen[leg.Ticker] = struct{}{}
+	}
+
+	return nil
+}
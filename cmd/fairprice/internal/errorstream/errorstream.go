@@ -0,0 +1,19 @@
+package errorstream
+
+import (
+	"context"
+
+	"tickerprice/internal/log"
+)
+
+// BufferSize is the number of errors buffered for a subscriber that does not read them in time.
+const BufferSize = 64
+
+// Report delivers the error or drops it if the subscriber does not keep up with reading errors.
+func Report(ctx context.Context, errors chan<- error, err error) {
+	select {
+	case errors <- err:
+	default:
+		log.Errorf(ctx, "error channel is full, drop error: %v", err)
+	}
+}
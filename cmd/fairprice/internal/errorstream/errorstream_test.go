@@ -0,0 +1,34 @@
+package errorstream_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/errorstream"
+)
+
+func TestReport(t *testing.T) {
+	var (
+		mockError     = errors.New("mock error")
+		mockDropped   = errors.New("mock dropped error")
+		errorsChannel = make(chan error, 1)
+	)
+
+	errorstream.Report(context.Background(), errorsChannel, mockError)
+
+	// the buffer is full, the error is dropped without blocking
+	errorstream.Report(context.Background(), errorsChannel, mockDropped)
+
+	close(errorsChannel)
+
+	var resultErrors []error
+
+	for err := range errorsChannel {
+		resultErrors = append(resultErrors, err)
+	}
+
+	assert.Equal(t, []error{mockError}, resultErrors)
+}
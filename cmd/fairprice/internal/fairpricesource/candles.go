@@ -0,0 +1,139 @@
+package fairpricesource
+
+import (
+	"fmt"
+	"sort"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// candle is an open/high/low/close bar of parsed prices.
+type candle struct {
+	open   decimal.Decimal
+	high   decimal.Decimal
+	low    decimal.Decimal
+	close  decimal.Decimal
+	volume decimal.Decimal
+}
+
+// buildCandles builds a candle for every source from its ticks.
+func buildCandles(ticks map[types.SourceID][]types.SourcePrice) map[types.SourceID]candle {
+	candles := make(map[types.SourceID]candle, len(ticks))
+
+	for sourceID, sourceTicks := range ticks {
+		if len(sourceTicks) == 0 {
+			continue
+		}
+
+		candles[sourceID] = buildCandle(sourceTicks)
+	}
+
+	return candles
+}
+
+func buildCandle(ticks []types.SourcePrice) candle {
+	sorted := make([]types.SourcePrice, len(ticks))
+	copy(sorted, ticks)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	c := candle{
+		open:  sorted[0].Price,
+		high:  sorted[0].Price,
+		low:   sorted[0].Price,
+		close: sorted[len(sorted)-1].Price,
+	}
+
+	for _, tick := range sorted {
+		if tick.Price.Cmp(c.high) > 0 {
+			c.high = tick.Price
+		}
+
+		if tick.Price.Cmp(c.low) < 0 {
+			c.low = tick.Price
+		}
+
+		c.volume = c.volume.Add(tick.Volume)
+	}
+
+	return c
+}
+
+// calculateFairCandle applies the price algorithm to every component of candles of the accepted sources.
+func (p *FairPriceSource) calculateFairCandle(
+	algorithm types.PriceAlgorithm,
+	candles map[types.SourceID]candle,
+	accepted map[types.SourceID]types.SourcePrice,
+) (candle, error) {
+	var fair candle
+
+	components := []struct {
+		name  string
+		value func(c candle) decimal.Decimal
+		fair  *decimal.Decimal
+	}{
+		{name: "open", value: func(c candle) decimal.Decimal { return c.open }, fair: &fair.open},
+		{name: "high", value: func(c candle) decimal.Decimal { return c.high }, fair: &fair.high},
+		{name: "low", value: func(c candle) decimal.Decimal { return c.low }, fair: &fair.low},
+		{name: "close", value: func(c candle) decimal.Decimal { return c.close }, fair: &fair.close},
+	}
+
+	for _, component := range components {
+		prices := make(map[types.SourceID]types.SourcePrice, len(accepted))
+
+		for sourceID, price := range accepted {
+			c, ok := candles[sourceID]
+			if !ok {
+				continue
+			}
+
+			prices[sourceID] = types.SourcePrice{
+				Time:    price.Time,
+				Price:   component.value(c),
+				Volume:  price.Volume,
+				Bid:     price.Bid,
+				Ask:     price.Ask,
+				Carried: price.Carried,
+			}
+		}
+
+		price, err := algorithm.CalculatePrice(prices)
+		if err != nil {
+			return candle{}, fmt.Errorf("%s: %w", component.name, err)
+		}
+
+		*component.fair = price
+	}
+
+	for sourceID := range accepted {
+		fair.volume = fair.volume.Add(candles[sourceID].volume)
+	}
+
+	return fair, nil
+}
+
+func (p *FairPriceSource) formatCandles(
+	ticker types.Ticker,
+	candles map[types.SourceID]candle,
+) map[types.SourceID]types.Candle {
+	formatted := make(map[types.SourceID]types.Candle, len(candles))
+
+	for sourceID, c := range candles {
+		formatted[sourceID] = p.formatCandle(ticker, c)
+	}
+
+	return formatted
+}
+
+func (p *FairPriceSource) formatCandle(ticker types.Ticker, c candle) types.Candle {
+	return types.Candle{
+		Open:   p.formatPrice(ticker, c.open),
+		High:   p.formatPrice(ticker, c.high),
+		Low:    p.formatPrice(ticker, c.low),
+		Close:  p.formatPrice(ticker, c.close),
+		Volume: formatVolume(c.volume),
+	}
+}
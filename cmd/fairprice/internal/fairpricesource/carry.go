@@ -0,0 +1,78 @@
+package fairpricesource
+
+import (
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+// carryForward adds the last known prices of sources without prices in the timeslot and remembers
+// the latest prices of sources. Carried prices are flagged, keep their quotes and have no volume,
+// a price is carried until it is older than the max age at the end of the timeslot.
+func (p *FairPriceSource) carryForward(
+	timeslot types.Timeslot,
+	prices map[types.SourceID]types.SourcePrice,
+	lastPrices map[types.SourceID]types.SourcePrice,
+) map[types.SourceID]types.SourcePrice {
+	if p.carryMaxAge == 0 {
+		return prices
+	}
+
+	for sourceID, price := range prices {
+		lastPrices[sourceID] = price
+	}
+
+	end := timeslot.EndTime(p.timeslotDuration)
+
+	carried := make(map[types.SourceID]types.SourcePrice, len(lastPrices))
+
+	for sourceID, price := range prices {
+		carried[sourceID] = price
+	}
+
+	for sourceID, lastPrice := range lastPrices {
+		if _, ok := prices[sourceID]; ok {
+			continue
+		}
+
+		if end.Sub(lastPrice.Time) > p.carryMaxAge {
+			delete(lastPrices, sourceID)
+			continue
+		}
+
+		carried[sourceID] = types.SourcePrice{
+			Time:    lastPrice.Time,
+			Price:   lastPrice.Price,
+			Bid:     lastPrice.Bid,
+			Ask:     lastPrice.Ask,
+			Carried: true,
+		}
+	}
+
+	return carried
+}
+
+// withCarriedCandles adds flat candles of carried prices to the candles of sources with ticks.
+func withCarriedCandles(
+	candles map[types.SourceID]candle,
+	prices map[types.SourceID]types.SourcePrice,
+) map[types.SourceID]candle {
+	all := make(map[types.SourceID]candle, len(prices))
+
+	for sourceID, c := range candles {
+		all[sourceID] = c
+	}
+
+	for sourceID, price := range prices {
+		if !price.Carried {
+			continue
+		}
+
+		all[sourceID] = candle{
+			open:  price.Price,
+			high:  price.Price,
+			low:   price.Price,
+			close: price.Price,
+		}
+	}
+
+	return all
+}
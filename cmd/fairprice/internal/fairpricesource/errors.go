@@ -0,0 +1,104 @@
+package fairpricesource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+var (
+	// ErrSourceDisconnected is reported when a subscription to a source ends with an error.
+	ErrSourceDisconnected = errors.New("source disconnected")
+
+	// ErrSourceFailed is reported when a source is given up after too many reconnect attempts.
+	ErrSourceFailed = errors.New("source failed")
+
+	// ErrSourceStale is reported when a source has not sent ticks for too long and is excluded from the fair price.
+	ErrSourceStale = errors.New("source stale")
+
+	// ErrOutOfOrder is reported when a source delivers a tick which is not later than its previous tick.
+	ErrOutOfOrder = errors.New("out of order tick")
+
+	// ErrParsePrice is reported when a price or a volume received from a source cannot be parsed.
+	ErrParsePrice = errors.New("parse price")
+
+	// ErrInvalidPrice is reported when a price received from a source is outside the sane bounds of the ticker.
+	ErrInvalidPrice = errors.New("invalid price")
+
+	// ErrInsufficientData is reported when a timeslot is skipped because no source provided a usable price.
+	ErrInsufficientData = errors.New("insufficient data")
+
+	// ErrCalculatePrice is reported when a timeslot is skipped because the fair price cannot be calculated.
+	ErrCalculatePrice = errors.New("calculate price")
+)
+
+// Error is an error of a subscription with the context it has happened in.
+// The kind of the error can be checked with errors.Is against the Err* values of the package.
+type Error struct {
+	Kind     error
+	SourceID types.SourceID // empty if the error is not related to a single source
+	Ticker   types.Ticker
+	Timeslot types.Timeslot // zero if the error is not related to a timeslot
+	Err      error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+
+	b.WriteString(e.Kind.Error())
+
+	fmt.Fprintf(&b, ": ticker %s", e.Ticker)
+
+	if e.SourceID != "" {
+		fmt.Fprintf(&b, ", source %s", e.SourceID)
+	}
+
+	if e.Timeslot != 0 {
+		fmt.Fprintf(&b, ", timeslot %v", e.Timeslot.ToTime().Format("2006-01-02T15:04:05Z"))
+	}
+
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %v", e.Err)
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error is of the target kind.
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// errorReporter delivers errors to the error channels of subscriptions to the ticker without blocking the pipeline.
+type errorReporter struct {
+	health        *healthTracker
+	subscriptions func(ticker types.Ticker) []*subscription
+}
+
+// newErrorReporter creates a new initialized instance of errorReporter.
+func newErrorReporter(health *healthTracker, subscriptions func(ticker types.Ticker) []*subscription) *errorReporter {
+	return &errorReporter{
+		health:        health,
+		subscriptions: subscriptions,
+	}
+}
+
+// Report delivers the error to every subscription to its ticker, it is dropped for a subscriber
+// which does not keep up with reading errors.
+func (r *errorReporter) Report(ctx context.Context, err *Error) {
+	if err.SourceID != "" {
+		r.health.Error(err.SourceID)
+	}
+
+	for _, s := range r.subscriptions(err.Ticker) {
+		s.sendError(ctx, err)
+	}
+}
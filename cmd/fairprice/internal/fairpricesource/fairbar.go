@@ -0,0 +1,150 @@
+package fairpricesource
+
+import (
+	"math"
+	"sort"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// dispersionTolerance is the standard deviation of prices of contributors relative to the fair price
+// at which the confidence is halved.
+const dispersionTolerance = 0.001
+
+// carriedCoverage is the share of a source with a carried price in the coverage of the confidence.
+const carriedCoverage = 0.5
+
+// buildFairBar describes the fair price of the timeslot calculated from prices of contributors.
+// Sources which are neither contributors nor excluded for another reason are excluded for missing prices.
+func (p *FairPriceSource) buildFairBar(
+	ticker types.Ticker,
+	timeslot types.Timeslot,
+	fairPrice decimal.Decimal,
+	status types.PriceStatus,
+	contributors map[types.SourceID]types.SourcePrice,
+	excluded map[types.SourceID]string,
+	ticks map[types.SourceID][]types.SourcePrice,
+) types.FairBar {
+	bar := types.FairBar{
+		Ticker:       ticker,
+		Time:         timeslot.ToTime(),
+		Price:        p.formatPrice(ticker, fairPrice),
+		Status:       status,
+		Contributors: make([]types.SourceID, 0, len(contributors)),
+		Excluded:     make(map[types.SourceID]string, len(excluded)),
+		TickCounts:   make(map[types.SourceID]int, len(ticks)),
+	}
+
+	for sourceID, price := range contributors {
+		bar.Contributors = append(bar.Contributors, sourceID)
+
+		if price.Carried {
+			bar.Carried = append(bar.Carried, sourceID)
+		}
+	}
+
+	sort.Slice(bar.Contributors, func(i, j int) bool { return bar.Contributors[i] < bar.Contributors[j] })
+	sort.Slice(bar.Carried, func(i, j int) bool { return bar.Carried[i] < bar.Carried[j] })
+
+	for sourceID, reason := range excluded {
+		bar.Excluded[sourceID] = reason
+	}
+
+	for sourceID := range p.subscribers {
+		_, contributes := contributors[sourceID]
+		_, isExcluded := excluded[sourceID]
+
+		if !contributes && !isExcluded {
+			bar.Excluded[sourceID] = "no prices in the timeslot"
+		}
+	}
+
+	for sourceID, sourceTicks := range ticks {
+		bar.TickCounts[sourceID] = len(sourceTicks)
+	}
+
+	if len(contributors) == 0 {
+		return bar
+	}
+
+	minPrice, maxPrice, stdDev := priceStats(contributors)
+
+	bar.Min = p.formatPrice(ticker, minPrice)
+	bar.Max = p.formatPrice(ticker, maxPrice)
+	bar.StdDev = p.formatPrice(ticker, stdDev)
+	bar.Confidence = p.confidence(fairPrice, stdDev, contributors)
+
+	return bar
+}
+
+// confidence is the coverage of sources reduced by the dispersion of prices of contributors.
+func (p *FairPriceSource) confidence(
+	fairPrice decimal.Decimal,
+	stdDev decimal.Decimal,
+	contributors map[types.SourceID]types.SourcePrice,
+) float64 {
+	if len(p.subscribers) == 0 {
+		return 0
+	}
+
+	var coverage float64
+
+	for _, price := range contributors {
+		if price.Carried {
+			coverage += carriedCoverage
+		} else {
+			coverage++
+		}
+	}
+
+	coverage /= float64(len(p.subscribers))
+
+	if fairPrice.IsZero() {
+		return coverage
+	}
+
+	dispersion := math.Abs(stdDev.Div(fairPrice).Float64())
+
+	return coverage / (1 + dispersion/dispersionTolerance)
+}
+
+// priceStats returns the lowest and the highest price and the population standard deviation of prices.
+func priceStats(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, decimal.Decimal, decimal.Decimal) {
+	var (
+		minPrice decimal.Decimal
+		maxPrice decimal.Decimal
+		sum      decimal.Decimal
+		first    = true
+	)
+
+	for _, price := range prices {
+		if first || price.Price.Cmp(minPrice) < 0 {
+			minPrice = price.Price
+		}
+
+		if first || price.Price.Cmp(maxPrice) > 0 {
+			maxPrice = price.Price
+		}
+
+		first = false
+
+		sum = sum.Add(price.Price)
+	}
+
+	count := decimal.NewFromInt(int64(len(prices)))
+
+	mean := sum.Div(count)
+
+	var squares decimal.Decimal
+
+	for _, price := range prices {
+		deviation := price.Price.Sub(mean)
+
+		squares = squares.Add(deviation.Mul(deviation))
+	}
+
+	variance := squares.Div(count)
+
+	return minPrice, maxPrice, decimal.NewFromFloat(math.Sqrt(variance.Float64()))
+}
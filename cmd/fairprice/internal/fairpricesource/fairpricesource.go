@@ -3,51 +3,140 @@ package fairpricesource
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"sync"
 	"time"
 
+	"tickerprice/cmd/fairprice/internal/tickaggregator"
+	"tickerprice/cmd/fairprice/internal/tickerregistry"
 	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
 	"tickerprice/internal/log"
 )
 
-//go:generate moq -pkg fairpricesource_test -out mocks_test.go . PriceAlgorithm PriceStorage
-//go:generate moq -pkg fairpricesource_test -out mocks_types_test.go ../types PriceStreamSubscriber
+//go:generate moq -pkg fairpricesource_test -out mocks_test.go . PriceFilter PriceStorage TickAggregator
+//go:generate moq -pkg fairpricesource_test -out mocks_types_test.go ../types MultiPriceStreamSubscriber PriceAlgorithm PriceStreamSubscriber
 
-// PriceAlgorithm is an algorithm for calculating a fair price based on an array of prices.
-type PriceAlgorithm interface {
-	// CalculatePrice calculates a fair price based on prices from different sources.
-	CalculatePrice(prices map[types.SourceID]float64) (float64, error)
+// TickerAlgorithm is implemented by algorithms learning from prices of a single ticker.
+// Prices of every ticker are calculated by the algorithm returned for the ticker.
+type TickerAlgorithm interface {
+	// ForTicker returns the algorithm calculating prices of the ticker.
+	ForTicker(ticker types.Ticker) types.PriceAlgorithm
+}
+
+// QuoteAlgorithm is an algorithm for calculating a fair bid and ask based on quotes of sources.
+type QuoteAlgorithm interface {
+	// CalculateQuote calculates a fair quote based on prices with quotes from different sources.
+	CalculateQuote(prices map[types.SourceID]types.SourcePrice) (types.Quote, error)
+}
+
+// FairPriceObserver is implemented by algorithms which learn from calculated fair prices.
+type FairPriceObserver interface {
+	// ObserveFairPrice is called once for every calculated fair price with the prices of all fresh sources
+	// of the timeslot, including prices rejected by the filter.
+	ObserveFairPrice(prices map[types.SourceID]types.SourcePrice, fairPrice decimal.Decimal)
+}
+
+// PriceFilter is a filter that rejects prices deviating from the consensus of other sources.
+type PriceFilter interface {
+	// FilterPrices splits prices into accepted and rejected ones.
+	FilterPrices(
+		prices map[types.SourceID]types.SourcePrice,
+	) (map[types.SourceID]types.SourcePrice, map[types.SourceID]types.Rejection)
 }
 
 // PriceStorage is a storage for prices.
 type PriceStorage interface {
-	AddPrice(ticker types.Ticker, timeslot types.Timeslot, sourceID types.SourceID, price string)
-	GetPrices(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID]string
+	AddPrice(ticker types.Ticker, timeslot types.Timeslot, sourceID types.SourceID, price types.TickerPrice)
+	GetPrices(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID][]types.TickerPrice
 	RemovePrices(ticker types.Ticker, timeslot types.Timeslot)
 }
 
+// TickAggregator is an aggregator of all prices reported by a single source during a timeslot.
+type TickAggregator interface {
+	// AggregateTicks reduces the series of prices of the timeslot between start and end to a single price.
+	AggregateTicks(start, end time.Time, ticks []types.SourcePrice) (types.SourcePrice, error)
+}
+
 // FairPriceSource is the source of the aggregated price from other sources.
+// All subscriptions to a ticker share a single pipeline whatever streams they are subscribed to,
+// so a consumer not reading its stream in time holds back other consumers of the ticker.
 type FairPriceSource struct {
-	algorithm   PriceAlgorithm
-	storage     PriceStorage
-	subscribers map[types.SourceID]types.PriceStreamSubscriber
-	timeNowFunc func() time.Time
+	algorithm        types.PriceAlgorithm
+	quoteAlgorithm   QuoteAlgorithm
+	filter           PriceFilter
+	aggregator       TickAggregator
+	storage          PriceStorage
+	subscribers      map[types.SourceID]types.PriceStreamSubscriber
+	timeslotDuration time.Duration
+	gracePeriod      time.Duration
+	reconnectPolicy  ReconnectPolicy
+	sourcePolicies   map[types.SourceID]ReconnectPolicy
+	symbolMaps       map[types.SourceID]SymbolMap
+	staleAfter       time.Duration
+	quorum           Quorum
+	orderPolicy      OrderPolicy
+	carryMaxAge      time.Duration
+	roundingMode     decimal.RoundingMode
+	registry         *tickerregistry.Registry
+	health           *healthTracker
+	timeNowFunc      func() time.Time
+	mutex            sync.Mutex // guards pipelines
+	pipelines        map[types.Ticker]*pipeline
 }
 
 // New creates a new initialized instance of FairPriceSource.
+// The timeslot duration must be a positive whole number of seconds.
+// Symbol maps must not map two tickers to the same symbol.
 func New(
-	algorithm PriceAlgorithm,
+	algorithm types.PriceAlgorithm,
 	storage PriceStorage,
 	subscribers map[types.SourceID]types.PriceStreamSubscriber,
+	timeslotDuration time.Duration,
 	timeNowFunc func() time.Time,
+	options ...Option,
 ) *FairPriceSource {
-	return &FairPriceSource{
-		algorithm:   algorithm,
-		storage:     storage,
-		subscribers: subscribers,
-		timeNowFunc: timeNowFunc,
+	if timeslotDuration < time.Second || timeslotDuration%time.Second != 0 {
+		panic(fmt.Sprintf("fairpricesource: invalid timeslot duration %v", timeslotDuration))
 	}
+
+	p := &FairPriceSource{
+		algorithm:        algorithm,
+		aggregator:       tickaggregator.NewLast(),
+		storage:          storage,
+		subscribers:      subscribers,
+		timeslotDuration: timeslotDuration,
+		reconnectPolicy:  DefaultReconnectPolicy,
+		sourcePolicies:   make(map[types.SourceID]ReconnectPolicy),
+		symbolMaps:       make(map[types.SourceID]SymbolMap),
+		registry:         tickerregistry.New(),
+		timeNowFunc:      timeNowFunc,
+		pipelines:        make(map[types.Ticker]*pipeline),
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	for sourceID, symbolMap := range p.symbolMaps {
+		if err := symbolMap.validate(); err != nil {
+			panic(fmt.Sprintf("fairpricesource: invalid symbol map of source %s: %v", sourceID, err))
+		}
+	}
+
+	sourceIDs := make([]types.SourceID, 0, len(subscribers))
+
+	for sourceID := range subscribers {
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+
+	p.health = newHealthTracker(sourceIDs, p.staleAfter)
+
+	return p
+}
+
+// Health returns the health of every source.
+func (p *FairPriceSource) Health() map[types.SourceID]SourceHealth {
+	return p.health.Snapshot()
 }
 
 // SubscribePriceStream subscribes to price updates from the source.
@@ -55,117 +144,438 @@ func (p *FairPriceSource) SubscribePriceStream(
 	ctx context.Context,
 	ticker types.Ticker,
 ) (<-chan types.TickerPrice, <-chan error) {
-	subscribersWaitGroup := sync.WaitGroup{}
+	return subscribe(ctx, p, []types.Ticker{ticker}, func(bar timeslotBar) types.TickerPrice {
+		return bar.price
+	})
+}
+
+// SubscribePriceStreams subscribes to price updates of several tickers from the source.
+// Prices of all tickers are merged into a single stream, sources supporting several tickers
+// share a single upstream subscription.
+func (p *FairPriceSource) SubscribePriceStreams(
+	ctx context.Context,
+	tickers []types.Ticker,
+) (<-chan types.TickerPrice, <-chan error) {
+	return subscribe(ctx, p, tickers, func(bar timeslotBar) types.TickerPrice {
+		return bar.price
+	})
+}
+
+// SubscribeFairBarStream subscribes to fair prices with the details of how they were calculated.
+// SubscribePriceStream delivers the same fair prices without the details.
+func (p *FairPriceSource) SubscribeFairBarStream(
+	ctx context.Context,
+	ticker types.Ticker,
+) (<-chan types.FairBar, <-chan error) {
+	return subscribe(ctx, p, []types.Ticker{ticker}, func(bar timeslotBar) types.FairBar {
+		return bar.fairBar
+	})
+}
+
+// SubscribeCandleStream subscribes to candle updates of every source and the fair candle across sources.
+func (p *FairPriceSource) SubscribeCandleStream(
+	ctx context.Context,
+	ticker types.Ticker,
+) (<-chan types.TickerCandles, <-chan error) {
+	return subscribe(ctx, p, []types.Ticker{ticker}, func(bar timeslotBar) types.TickerCandles {
+		return bar.candles
+	})
+}
 
-	for sourceID, subscriber := range p.subscribers {
-		subscribersWaitGroup.Add(1)
+// timeslotBar is everything calculated for a timeslot.
+type timeslotBar struct {
+	fairPrice decimal.Decimal
+	fairBar   types.FairBar
+	price     types.TickerPrice
+	candles   types.TickerCandles
+}
+
+func subscribe[T any](
+	ctx context.Context,
+	p *FairPriceSource,
+	tickers []types.Ticker,
+	convert func(bar timeslotBar) T,
+) (<-chan T, <-chan error) {
+	tickers = uniqueTickers(tickers)
 
-		go func(sourceID types.SourceID, subscriber types.PriceStreamSubscriber) {
-			defer subscribersWaitGroup.Done()
+	s := newSubscription(ctx)
 
-			p.runSubscriber(ctx, ticker, sourceID, subscriber)
-		}(sourceID, subscriber)
-	}
+	outTickerBars := make(chan T)
 
-	outTickerPrices := make(chan types.TickerPrice)
-	outTickerErrors := make(chan error)
+	if !p.attach(s, tickers) {
+		close(outTickerBars)
+		s.close()
+
+		return outTickerBars, s.errors
+	}
 
 	go func() {
-		defer func() {
-			subscribersWaitGroup.Wait()
+		defer close(outTickerBars)
+		defer s.close()
 
-			close(outTickerPrices)
-			close(outTickerErrors)
+		// the last consumer of a pipeline gets its channels closed once the pipeline has stopped
+		defer func() {
+			for _, done := range p.detach(s, tickers) {
+				<-done
+			}
 		}()
 
-		p.runPublisher(ctx, ticker, outTickerPrices)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case bar := <-s.bars:
+				select {
+				case <-ctx.Done():
+					return
+
+				case outTickerBars <- convert(bar):
+				}
+			}
+		}
 	}()
 
-	return outTickerPrices, outTickerErrors
+	return outTickerBars, s.errors
+}
+
+// splitSubscriptions groups tickers into upstream subscriptions of the subscriber.
+// Subscribers supporting several tickers get a single subscription, others get a subscription per ticker.
+func splitSubscriptions(subscriber types.PriceStreamSubscriber, tickers []types.Ticker) [][]types.Ticker {
+	if _, ok := subscriber.(types.MultiPriceStreamSubscriber); ok && len(tickers) > 1 {
+		return [][]types.Ticker{tickers}
+	}
+
+	subscriptions := make([][]types.Ticker, 0, len(tickers))
+
+	for _, ticker := range tickers {
+		subscriptions = append(subscriptions, []types.Ticker{ticker})
+	}
+
+	return subscriptions
+}
+
+func subscribeTickers(
+	ctx context.Context,
+	subscriber types.PriceStreamSubscriber,
+	tickers []types.Ticker,
+) (<-chan types.TickerPrice, <-chan error) {
+	if len(tickers) == 1 {
+		return subscriber.SubscribePriceStream(ctx, tickers[0])
+	}
+
+	return subscriber.(types.MultiPriceStreamSubscriber).SubscribePriceStreams(ctx, tickers)
+}
+
+func uniqueTickers(tickers []types.Ticker) []types.Ticker {
+	seen := make(map[types.Ticker]struct{}, len(tickers))
+	unique := make([]types.Ticker, 0, len(tickers))
+
+	for _, ticker := range tickers {
+		if _, ok := seen[ticker]; ok {
+			continue
+		}
+
+		seen[ticker] = struct{}{}
+		unique = append(unique, ticker)
+	}
+
+	return unique
 }
 
 func (p *FairPriceSource) runSubscriber(
 	ctx context.Context,
-	ticker types.Ticker,
+	tickers []types.Ticker,
 	sourceID types.SourceID,
 	subscriber types.PriceStreamSubscriber,
+	progresses map[types.Ticker]*progress,
+	reporter *errorReporter,
 ) {
-	reconnectWithDelay(ctx, func() {
-		tickerPrices, tickerErrors := subscriber.SubscribePriceStream(ctx, ticker)
+	policy, ok := p.sourcePolicies[sourceID]
+	if !ok {
+		policy = p.reconnectPolicy
+	}
+
+	subscription := p.health.AddSubscription(sourceID, tickers, p.timeNowFunc())
+
+	// a failed subscription stays in the health of the source until the consumer leaves
+	defer func() {
+		<-ctx.Done()
+		p.health.RemoveSubscription(sourceID, subscription)
+	}()
+
+	symbols, err := newSymbols(p.symbolMaps[sourceID], tickers)
+	if err != nil {
+		p.health.Failed(sourceID, subscription)
+
+		for _, ticker := range tickers {
+			reporter.Report(ctx, &Error{
+				Kind:     ErrSourceFailed,
+				SourceID: sourceID,
+				Ticker:   ticker,
+				Err:      fmt.Errorf("symbol map: %w", err),
+			})
+		}
+
+		return
+	}
+
+	var attempts int
+
+	// streams must deliver ticks in strictly increasing time order, it is checked across reconnects
+	lastTickTimes := make(map[types.Ticker]time.Time, len(tickers))
+
+	active := reconnectWithBackoff(ctx, policy, func() {
+		p.health.Connecting(sourceID, subscription, attempts > 0)
+
+		attempts++
+
+		subscriptionCtx, cancelSubscription := context.WithCancel(ctx)
+		defer cancelSubscription()
+
+		tickerPrices, tickerErrors := subscribeTickers(subscriptionCtx, subscriber, symbols.Native(tickers))
+
+		p.health.Connected(sourceID, subscription)
+
+		defer p.health.Disconnected(sourceID, subscription)
+
+		var disconnected bool
 
 		for tickerPrice := range tickerPrices {
-			timeslot := calculateTimeslot(tickerPrice.Time)
+			// the subscription is cancelled, wait for the source to close the channels
+			if disconnected {
+				continue
+			}
+
+			p.health.Tick(sourceID, tickerPrice.Time, p.timeNowFunc())
+
+			ticker, ok := symbols.Canonical(tickerPrice.Ticker)
+
+			// a single ticker subscription delivers only prices of that ticker
+			if len(tickers) == 1 {
+				ticker, ok = tickers[0], true
+			}
+
+			if !ok {
+				log.Errorf(ctx, "drop tick of unknown ticker: source %s, ticker %s", sourceID, tickerPrice.Ticker)
+				continue
+			}
+
+			progress := progresses[ticker]
+
+			tickerPrice.Ticker = ticker
+
+			inOrder := p.checkOrder(ctx, sourceID, ticker, tickerPrice.Time, lastTickTimes, reporter)
+
+			if !inOrder && p.orderPolicy != OrderAccept {
+				if p.orderPolicy == OrderDisconnect {
+					disconnected = true
+					cancelSubscription()
+				}
+
+				continue
+			}
+
+			timeslot := types.NewTimeslot(tickerPrice.Time, p.timeslotDuration)
+
+			stored := progress.Advance(sourceID, tickerPrice.Time, timeslot, func() {
+				p.storage.AddPrice(ticker, timeslot, sourceID, tickerPrice)
+			})
 
-			p.storage.AddPrice(ticker, timeslot, sourceID, tickerPrice.Price)
+			// the timeslot has already been published, the tick is too late
+			if !stored {
+				log.Errorf(ctx, "drop late tick: source %s, time %v", sourceID, tickerPrice.Time)
+			}
 		}
 
 		// stream can return an error, in that case the channel is closed
 		for tickerError := range tickerErrors {
-			log.Errorf(ctx, "subscription: %v", tickerError)
+			for _, ticker := range tickers {
+				reporter.Report(ctx, &Error{
+					Kind:     ErrSourceDisconnected,
+					SourceID: sourceID,
+					Ticker:   ticker,
+					Err:      tickerError,
+				})
+			}
 		}
 	})
+
+	if !active {
+		p.health.Failed(sourceID, subscription)
+
+		for _, ticker := range tickers {
+			reporter.Report(ctx, &Error{
+				Kind:     ErrSourceFailed,
+				SourceID: sourceID,
+				Ticker:   ticker,
+				Err:      fmt.Errorf("gave up after %d attempts", policy.MaxAttempts),
+			})
+		}
+	}
 }
 
 func (p *FairPriceSource) runPublisher(
 	ctx context.Context,
 	ticker types.Ticker,
-	outTickerPrices chan<- types.TickerPrice,
+	progress *progress,
+	reporter *errorReporter,
+	publish func(bar timeslotBar) bool,
 ) {
-	p.executeAtTimeslotEnd(ctx, func(timeslot types.Timeslot) {
-		stringPrices := p.storage.GetPrices(ticker, timeslot)
+	var previous *timeslotBar
 
-		prices := parsePrices(ctx, stringPrices)
+	// the latest prices of sources carried forward into timeslots without their prices
+	lastPrices := make(map[types.SourceID]types.SourcePrice)
 
-		fairPrice, err := p.algorithm.CalculatePrice(prices)
+	// stale sources already reported to the consumer of the ticker
+	reportedStale := make(map[types.SourceID]struct{})
+
+	p.executeAtTimeslotEnd(ctx, progress, func(timeslot types.Timeslot) {
+		bar, err := p.calculateBar(ctx, ticker, timeslot, previous, lastPrices, reportedStale, reporter)
 		if err != nil {
-			log.Errorf(ctx, "calculate fair price: %v", err)
+			reporter.Report(ctx, err)
 			return
 		}
 
-		fairTickerPrice := types.TickerPrice{
-			Ticker: ticker,
-			Time:   timeslot.ToTime(),
-			Price:  formatPrice(fairPrice),
-		}
+		previous = &bar
 
-		select {
-		case <-ctx.Done():
-			return
-
-		case outTickerPrices <- fairTickerPrice:
+		if publish(bar) {
 			p.storage.RemovePrices(ticker, timeslot)
 		}
 	})
 }
 
-func (p *FairPriceSource) executeAtTimeslotEnd(ctx context.Context, fn func(timeslot types.Timeslot)) {
-	currentTimeslot := calculateTimeslot(p.timeNowFunc())
+func (p *FairPriceSource) calculateBar(
+	ctx context.Context,
+	ticker types.Ticker,
+	timeslot types.Timeslot,
+	previous *timeslotBar,
+	lastPrices map[types.SourceID]types.SourcePrice,
+	reportedStale map[types.SourceID]struct{},
+	reporter *errorReporter,
+) (timeslotBar, *Error) {
+	tickerPrices := p.storage.GetPrices(ticker, timeslot)
+
+	ticks := p.parseTicks(ctx, ticker, timeslot, tickerPrices, reporter)
+
+	// silent sources are recorded with no ticks
+	for sourceID := range p.subscribers {
+		p.health.SlotTicks(sourceID, ticker, len(ticks[sourceID]))
+	}
+
+	prices := p.aggregateTicks(ctx, timeslot, ticks)
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
+	prices = p.carryForward(timeslot, prices, lastPrices)
+
+	// reasons of sources excluded from the fair price
+	excluded := make(map[types.SourceID]string)
+
+	fresh := p.excludeStale(ctx, ticker, timeslot, prices, excluded, reportedStale, reporter)
+
+	prices = p.filterPrices(ctx, fresh, excluded)
 
-		case <-time.After(time.Second):
-			timeslot := calculateTimeslot(p.timeNowFunc())
+	algorithm := p.tickerAlgorithm(ticker)
 
-			// wait for the next timeslot
-			if timeslot != currentTimeslot {
-				fn(currentTimeslot)
+	// prices of sources without weight are still passed to algorithms which ignore them anyway
+	contributors := p.excludeUnweighted(algorithm, prices, excluded)
 
-				currentTimeslot = timeslot
+	status := types.PriceStatusOK
+
+	if err := p.checkQuorum(algorithm, contributors); err != nil {
+		switch {
+		case p.quorum.Action == QuorumCarryForward && previous != nil:
+			return p.carryForwardBar(ticker, timeslot, ticks, contributors, excluded, previous), nil
+
+		case p.quorum.Action == QuorumLowConfidence && len(contributors) > 0:
+			status = types.PriceStatusLowConfidence
+
+		default:
+			return timeslotBar{}, &Error{
+				Kind:     ErrInsufficientData,
+				Ticker:   ticker,
+				Timeslot: timeslot,
+				Err:      err,
 			}
 		}
 	}
+
+	if len(prices) == 0 {
+		return timeslotBar{}, &Error{
+			Kind:     ErrInsufficientData,
+			Ticker:   ticker,
+			Timeslot: timeslot,
+			Err:      fmt.Errorf("no prices from %d sources", len(tickerPrices)),
+		}
+	}
+
+	fairPrice, err := algorithm.CalculatePrice(prices)
+	if err != nil {
+		return timeslotBar{}, &Error{
+			Kind:     ErrCalculatePrice,
+			Ticker:   ticker,
+			Timeslot: timeslot,
+			Err:      fmt.Errorf("fair price: %w", err),
+		}
+	}
+
+	candles := buildCandles(ticks)
+
+	fairCandle, err := p.calculateFairCandle(algorithm, withCarriedCandles(candles, prices), prices)
+	if err != nil {
+		return timeslotBar{}, &Error{
+			Kind:     ErrCalculatePrice,
+			Ticker:   ticker,
+			Timeslot: timeslot,
+			Err:      fmt.Errorf("fair candle: %w", err),
+		}
+	}
+
+	if observer, ok := algorithm.(FairPriceObserver); ok {
+		observer.ObserveFairPrice(fresh, fairPrice)
+	}
+
+	fairBar := p.buildFairBar(ticker, timeslot, fairPrice, status, contributors, excluded, ticks)
+
+	if quote, ok, err := p.calculateQuote(algorithm, prices); err != nil {
+		// the fair price is still published without the quote
+		reporter.Report(ctx, &Error{
+			Kind:     ErrCalculatePrice,
+			Ticker:   ticker,
+			Timeslot: timeslot,
+			Err:      fmt.Errorf("fair quote: %w", err),
+		})
+	} else if ok {
+		p.setQuote(&fairBar, quote)
+	}
+
+	return timeslotBar{
+		fairPrice: fairPrice,
+		fairBar:   fairBar,
+		price:     fairBar.TickerPrice(),
+		candles: types.TickerCandles{
+			Ticker:  ticker,
+			Time:    timeslot.ToTime(),
+			Fair:    p.formatCandle(ticker, fairCandle),
+			Sources: p.formatCandles(ticker, candles),
+			Status:  status,
+		},
+	}, nil
 }
 
-func parsePrices(ctx context.Context, stringPrices map[types.SourceID]string) map[types.SourceID]float64 {
-	prices := make(map[types.SourceID]float64, len(stringPrices))
+func (p *FairPriceSource) aggregateTicks(
+	ctx context.Context,
+	timeslot types.Timeslot,
+	ticks map[types.SourceID][]types.SourcePrice,
+) map[types.SourceID]types.SourcePrice {
+	start := timeslot.ToTime()
+	end := timeslot.EndTime(p.timeslotDuration)
+
+	prices := make(map[types.SourceID]types.SourcePrice, len(ticks))
 
-	for sourceID, stringPrice := range stringPrices {
-		price, err := parsePrice(stringPrice)
+	for sourceID, sourceTicks := range ticks {
+		price, err := p.aggregator.AggregateTicks(start, end, sourceTicks)
 		if err != nil {
-			log.Errorf(ctx, "parse price: %v", err)
+			log.Errorf(ctx, "aggregate ticks: source %s: %v", sourceID, err)
 			continue
 		}
 
@@ -175,38 +585,299 @@ func parsePrices(ctx context.Context, stringPrices map[types.SourceID]string) ma
 	return prices
 }
 
-func parsePrice(s string) (float64, error) {
-	f, err := strconv.ParseFloat(s, 64)
-	if err != nil {
-		return 0, fmt.Errorf("parse float: %w", err)
+// tickerAlgorithm returns the price algorithm calculating prices of the ticker.
+func (p *FairPriceSource) tickerAlgorithm(ticker types.Ticker) types.PriceAlgorithm {
+	if algorithm, ok := p.algorithm.(TickerAlgorithm); ok {
+		return algorithm.ForTicker(ticker)
 	}
 
-	return f, nil
+	return p.algorithm
 }
 
-func formatPrice(f float64) string {
-	return strconv.FormatFloat(f, 'f', 10, 64)
+// excludeStale removes prices of sources which have not sent ticks for too long.
+// Every publisher reports a source once each time it becomes stale, reportedStale keeps the reported ones.
+func (p *FairPriceSource) excludeStale(
+	ctx context.Context,
+	ticker types.Ticker,
+	timeslot types.Timeslot,
+	prices map[types.SourceID]types.SourcePrice,
+	excluded map[types.SourceID]string,
+	reportedStale map[types.SourceID]struct{},
+	reporter *errorReporter,
+) map[types.SourceID]types.SourcePrice {
+	stale := p.health.CheckStale(p.timeNowFunc())
+
+	// a source which has recovered is reported again when it becomes stale next time
+	for sourceID := range reportedStale {
+		if _, ok := stale[sourceID]; !ok {
+			delete(reportedStale, sourceID)
+		}
+	}
+
+	for sourceID := range stale {
+		if _, ok := reportedStale[sourceID]; ok {
+			continue
+		}
+
+		reportedStale[sourceID] = struct{}{}
+
+		reporter.Report(ctx, &Error{
+			Kind:     ErrSourceStale,
+			SourceID: sourceID,
+			Ticker:   ticker,
+			Timeslot: timeslot,
+			Err:      fmt.Errorf("no ticks for more than %v", p.staleAfter),
+		})
+	}
+
+	fresh := make(map[types.SourceID]types.SourcePrice, len(prices))
+
+	for sourceID, price := range prices {
+		if _, ok := stale[sourceID]; ok {
+			excluded[sourceID] = fmt.Sprintf("stale, no ticks for more than %v", p.staleAfter)
+			continue
+		}
+
+		fresh[sourceID] = price
+	}
+
+	return fresh
 }
 
-func calculateTimeslot(t time.Time) types.Timeslot {
-	t = t.UTC()
+func (p *FairPriceSource) filterPrices(
+	ctx context.Context,
+	prices map[types.SourceID]types.SourcePrice,
+	excluded map[types.SourceID]string,
+) map[types.SourceID]types.SourcePrice {
+	if p.filter == nil {
+		return prices
+	}
+
+	accepted, rejected := p.filter.FilterPrices(prices)
 
-	year, month, day := t.Date()
+	for sourceID, rejection := range rejected {
+		log.Errorf(ctx, "reject price: source %s, price %v, deviation %v: %s",
+			sourceID, rejection.Price, rejection.Deviation, rejection.Reason)
 
-	// the start of the time slot is the same as the start of the minute in UTC
-	return types.Timeslot(time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, t.Location()).Unix())
+		excluded[sourceID] = fmt.Sprintf("rejected by filter: %s", rejection.Reason)
+	}
+
+	return accepted
 }
 
-func reconnectWithDelay(ctx context.Context, connect func()) {
-	// check context cancellation before repeat
-	for ctx.Err() == nil {
-		connect()
+// executeAtTimeslotEnd calls the function for every timeslot in order once the timeslot is finalized.
+// A timeslot is finalized when every source has delivered a tick after its end or the grace period has expired.
+func (p *FairPriceSource) executeAtTimeslotEnd(
+	ctx context.Context,
+	progress *progress,
+	fn func(timeslot types.Timeslot),
+) {
+	// check often enough to notice the end of short timeslots in time
+	checkInterval := time.Second
+	if p.timeslotDuration/2 < checkInterval {
+		checkInterval = p.timeslotDuration / 2
+	}
 
-		// avoid the retries storm
+	for {
 		select {
-		case <-time.After(time.Second):
 		case <-ctx.Done():
 			return
+
+		case <-time.After(checkInterval):
+			for {
+				timeslot := progress.Pending()
+				end := timeslot.EndTime(p.timeslotDuration)
+				now := p.timeNowFunc()
+
+				// wait for the end of the timeslot
+				if now.Before(end) {
+					break
+				}
+
+				// wait for late data from sources
+				if !progress.Passed(end) && now.Before(end.Add(p.gracePeriod)) {
+					break
+				}
+
+				progress.Finalize(types.NewTimeslot(end, p.timeslotDuration))
+
+				fn(timeslot)
+			}
 		}
 	}
 }
+
+// parseTicks parses prices of sources and drops prices outside the sane bounds of the ticker.
+func (p *FairPriceSource) parseTicks(
+	ctx context.Context,
+	ticker types.Ticker,
+	timeslot types.Timeslot,
+	tickerPrices map[types.SourceID][]types.TickerPrice,
+	reporter *errorReporter,
+) map[types.SourceID][]types.SourcePrice {
+	info, registered := p.registry.Lookup(ticker)
+
+	ticks := make(map[types.SourceID][]types.SourcePrice, len(tickerPrices))
+
+	for sourceID, series := range tickerPrices {
+		sourceTicks := make([]types.SourcePrice, 0, len(series))
+
+		for _, tickerPrice := range series {
+			quote, err := parseQuote(tickerPrice.Bid, tickerPrice.Ask)
+			if err != nil {
+				reporter.Report(ctx, &Error{
+					Kind:     ErrParsePrice,
+					SourceID: sourceID,
+					Ticker:   ticker,
+					Timeslot: timeslot,
+					Err:      fmt.Errorf("quote: %w", err),
+				})
+
+				continue
+			}
+
+			price, err := parseTickPrice(tickerPrice.Price, quote)
+			if err != nil {
+				reporter.Report(ctx, &Error{
+					Kind:     ErrParsePrice,
+					SourceID: sourceID,
+					Ticker:   ticker,
+					Timeslot: timeslot,
+					Err:      err,
+				})
+
+				continue
+			}
+
+			if registered {
+				if err := checkTickPrice(info, price, quote); err != nil {
+					reporter.Report(ctx, &Error{
+						Kind:     ErrInvalidPrice,
+						SourceID: sourceID,
+						Ticker:   ticker,
+						Timeslot: timeslot,
+						Err:      err,
+					})
+
+					continue
+				}
+			}
+
+			volume, err := parseVolume(tickerPrice.Volume)
+			if err != nil {
+				reporter.Report(ctx, &Error{
+					Kind:     ErrParsePrice,
+					SourceID: sourceID,
+					Ticker:   ticker,
+					Timeslot: timeslot,
+					Err:      fmt.Errorf("volume: %w", err),
+				})
+
+				continue
+			}
+
+			sourceTicks = append(sourceTicks, types.SourcePrice{
+				Time:   tickerPrice.Time,
+				Price:  price,
+				Volume: volume,
+				Bid:    quote.Bid,
+				Ask:    quote.Ask,
+			})
+		}
+
+		if len(sourceTicks) > 0 {
+			ticks[sourceID] = sourceTicks
+		}
+	}
+
+	return ticks
+}
+
+func parsePrice(s string) (decimal.Decimal, error) {
+	d, err := decimal.Parse(s)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("parse decimal: %w", err)
+	}
+
+	return d, nil
+}
+
+// parseTickPrice parses the price of a tick, the mid of the quote is used by sources which report quotes only.
+func parseTickPrice(s string, quote types.Quote) (decimal.Decimal, error) {
+	if s == "" && !quote.Bid.IsZero() {
+		return quote.Mid(), nil
+	}
+
+	return parsePrice(s)
+}
+
+// parseQuote parses the optional bid and ask of a tick, both must be reported and must not be crossed.
+func parseQuote(bid, ask string) (types.Quote, error) {
+	if bid == "" && ask == "" {
+		return types.Quote{}, nil
+	}
+
+	if bid == "" || ask == "" {
+		return types.Quote{}, fmt.Errorf("bid %q and ask %q must be reported together", bid, ask)
+	}
+
+	bidPrice, err := parsePrice(bid)
+	if err != nil {
+		return types.Quote{}, fmt.Errorf("bid: %w", err)
+	}
+
+	askPrice, err := parsePrice(ask)
+	if err != nil {
+		return types.Quote{}, fmt.Errorf("ask: %w", err)
+	}
+
+	if bidPrice.Sign() <= 0 || askPrice.Sign() <= 0 {
+		return types.Quote{}, fmt.Errorf("bid %v and ask %v must be positive", bidPrice, askPrice)
+	}
+
+	if bidPrice.Cmp(askPrice) > 0 {
+		return types.Quote{}, fmt.Errorf("bid %v is above ask %v", bidPrice, askPrice)
+	}
+
+	return types.Quote{Bid: bidPrice, Ask: askPrice}, nil
+}
+
+// checkTickPrice checks the price and the quote of a tick against the sane bounds of the ticker.
+func checkTickPrice(info tickerregistry.TickerInfo, price decimal.Decimal, quote types.Quote) error {
+	if err := info.CheckPrice(price); err != nil {
+		return err
+	}
+
+	if quote.Bid.IsZero() {
+		return nil
+	}
+
+	if err := info.CheckPrice(quote.Bid); err != nil {
+		return fmt.Errorf("bid: %w", err)
+	}
+
+	if err := info.CheckPrice(quote.Ask); err != nil {
+		return fmt.Errorf("ask: %w", err)
+	}
+
+	return nil
+}
+
+func parseVolume(s string) (decimal.Decimal, error) {
+	// volume is optional
+	if s == "" {
+		return decimal.Zero, nil
+	}
+
+	return parsePrice(s)
+}
+
+// formatPrice rounds the price with the rounding mode of the source, see Registry.FormatPrice.
+func (p *FairPriceSource) formatPrice(ticker types.Ticker, d decimal.Decimal) string {
+	return p.registry.FormatPrice(ticker, d, p.roundingMode)
+}
+
+// formatVolume prints the volume exactly, volumes are sums of reported decimals.
+func formatVolume(d decimal.Decimal) string {
+	return d.String()
+}
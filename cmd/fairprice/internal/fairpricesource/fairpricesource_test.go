@@ -2,15 +2,129 @@ package fairpricesource_test
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"tickerprice/cmd/fairprice/internal/averagealgorithm"
 	"tickerprice/cmd/fairprice/internal/fairpricesource"
+	"tickerprice/cmd/fairprice/internal/memstorage"
+	"tickerprice/cmd/fairprice/internal/quotealgorithm"
+	"tickerprice/cmd/fairprice/internal/reliabilityalgorithm"
+	"tickerprice/cmd/fairprice/internal/tickerregistry"
 	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
 )
 
+// mockSettleDelay is the time given to the fair price source to store ticks of mock sources
+// before the mock clock is moved past the end of their timeslot.
+const mockSettleDelay = 100 * time.Millisecond
+
+// mockClock is a clock moved forward by a test while the fair price source reads it.
+type mockClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func newMockClock(now time.Time) *mockClock {
+	return &mockClock{now: now}
+}
+
+func (c *mockClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *mockClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = now
+}
+
+// SetAfter moves the clock once ticks of mock sources are stored.
+func (c *mockClock) SetAfter(now time.Time) {
+	time.AfterFunc(mockSettleDelay, func() {
+		c.Set(now)
+	})
+}
+
+// newMockSource creates a source delivering the ticks of the subscribed ticker on every subscription.
+// The source stays connected until the subscription is cancelled.
+func newMockSource(ticks ...types.TickerPrice) *PriceStreamSubscriberMock {
+	return &PriceStreamSubscriberMock{
+		SubscribePriceStreamFunc: func(
+			ctx context.Context,
+			ticker types.Ticker,
+		) (
+			<-chan types.TickerPrice,
+			<-chan error,
+		) {
+			tickers := make(chan types.TickerPrice, len(ticks))
+			errors := make(chan error)
+
+			for _, tick := range ticks {
+				if tick.Ticker == ticker {
+					tickers <- tick
+				}
+			}
+
+			go func() {
+				<-ctx.Done()
+				close(tickers)
+				close(errors)
+			}()
+
+			return tickers, errors
+		},
+	}
+}
+
+// newMockMultiSource creates a source delivering all the ticks on every subscription to many tickers.
+// The source stays connected until the subscription is cancelled.
+func newMockMultiSource(ticks ...types.TickerPrice) *MultiPriceStreamSubscriberMock {
+	return &MultiPriceStreamSubscriberMock{
+		SubscribePriceStreamsFunc: func(
+			ctx context.Context,
+			tickers []types.Ticker,
+		) (
+			<-chan types.TickerPrice,
+			<-chan error,
+		) {
+			tickerPrices := make(chan types.TickerPrice, len(ticks))
+			errors := make(chan error)
+
+			for _, tick := range ticks {
+				tickerPrices <- tick
+			}
+
+			go func() {
+				<-ctx.Done()
+				close(tickerPrices)
+				close(errors)
+			}()
+
+			return tickerPrices, errors
+		},
+	}
+}
+
+// newMockSubscribers creates a mock source for every source with its ticks.
+func newMockSubscribers(ticks map[types.SourceID][]types.TickerPrice) map[types.SourceID]types.PriceStreamSubscriber {
+	subscribers := make(map[types.SourceID]types.PriceStreamSubscriber, len(ticks))
+
+	for sourceID, sourceTicks := range ticks {
+		subscribers[sourceID] = newMockSource(sourceTicks...)
+	}
+
+	return subscribers
+}
+
 func TestFairPriceSource_SubscribePriceStream(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -20,11 +134,11 @@ func TestFairPriceSource_SubscribePriceStream(t *testing.T) {
 
 		mockPrice1    = "1.0"
 		mockPrice2    = "3.0"
-		mockFairPrice = "2.0000000000"
+		mockFairPrice = "2"
 
-		mockPriceFloat1    = 1.0
-		mockPriceFloat2    = 3.0
-		mockFairPriceFloat = 2.0
+		mockPriceDecimal1    = decimal.MustParse(mockPrice1)
+		mockPriceDecimal2    = decimal.MustParse(mockPrice2)
+		mockFairPriceDecimal = decimal.MustParse(mockFairPrice)
 
 		mockTimeslot = types.Timeslot(60)
 
@@ -40,51 +154,9 @@ func TestFairPriceSource_SubscribePriceStream(t *testing.T) {
 			Price:  mockPrice2,
 		}
 
-		mockSource1 = &PriceStreamSubscriberMock{
-			SubscribePriceStreamFunc: func(
-				ctx context.Context,
-				ticker types.Ticker,
-			) (
-				<-chan types.TickerPrice,
-				<-chan error,
-			) {
-				tickers := make(chan types.TickerPrice, 1)
-				errors := make(chan error, 1)
-
-				go func() {
-					<-ctx.Done()
-					close(tickers)
-					close(errors)
-				}()
-
-				tickers <- mockTickerPrice1
-
-				return tickers, errors
-			},
-		}
-
-		mockSource2 = &PriceStreamSubscriberMock{
-			SubscribePriceStreamFunc: func(
-				ctx context.Context,
-				ticker types.Ticker,
-			) (
-				<-chan types.TickerPrice,
-				<-chan error,
-			) {
-				tickers := make(chan types.TickerPrice, 1)
-				errors := make(chan error, 1)
-
-				go func() {
-					<-ctx.Done()
-					close(tickers)
-					close(errors)
-				}()
-
-				tickers <- mockTickerPrice2
+		mockSource1 = newMockSource(mockTickerPrice1)
 
-				return tickers, errors
-			},
-		}
+		mockSource2 = newMockSource(mockTickerPrice2)
 
 		mockSourceID1 = types.SourceID("source_1")
 		mockSourceID2 = types.SourceID("source_2")
@@ -94,17 +166,17 @@ func TestFairPriceSource_SubscribePriceStream(t *testing.T) {
 				ticker types.Ticker,
 				timeslot types.Timeslot,
 				sourceID types.SourceID,
-				price string,
+				price types.TickerPrice,
 			) {
 				switch sourceID {
 				case mockSourceID1:
 					assert.Equal(t, mockTicker, ticker)
 					assert.Equal(t, mockTimeslot, timeslot)
-					assert.Equal(t, mockPrice1, price)
+					assert.Equal(t, mockTickerPrice1, price)
 				case mockSourceID2:
 					assert.Equal(t, mockTicker, ticker)
 					assert.Equal(t, mockTimeslot, timeslot)
-					assert.Equal(t, mockPrice2, price)
+					assert.Equal(t, mockTickerPrice2, price)
 				default:
 					t.Fail()
 				}
@@ -112,10 +184,10 @@ func TestFairPriceSource_SubscribePriceStream(t *testing.T) {
 			GetPricesFunc: func(
 				ticker types.Ticker,
 				timeslot types.Timeslot,
-			) map[types.SourceID]string {
-				return map[types.SourceID]string{
-					mockSourceID1: mockPrice1,
-					mockSourceID2: mockPrice2,
+			) map[types.SourceID][]types.TickerPrice {
+				return map[types.SourceID][]types.TickerPrice{
+					mockSourceID1: {mockTickerPrice1},
+					mockSourceID2: {mockTickerPrice2},
 				}
 			},
 			RemovePricesFunc: func(
@@ -128,15 +200,15 @@ func TestFairPriceSource_SubscribePriceStream(t *testing.T) {
 		}
 
 		mockAlgorithm = &PriceAlgorithmMock{
-			CalculatePriceFunc: func(prices map[types.SourceID]float64) (float64, error) {
-				expectedPrices := map[types.SourceID]float64{
-					mockSourceID1: mockPriceFloat1,
-					mockSourceID2: mockPriceFloat2,
+			CalculatePriceFunc: func(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+				expectedPrices := map[types.SourceID]types.SourcePrice{
+					mockSourceID1: {Time: mockTickerPrice1.Time, Price: mockPriceDecimal1},
+					mockSourceID2: {Time: mockTickerPrice2.Time, Price: mockPriceDecimal2},
 				}
 
 				assert.Equal(t, expectedPrices, prices)
 
-				return mockFairPriceFloat, nil
+				return mockFairPriceDecimal, nil
 			},
 		}
 
@@ -146,18 +218,10 @@ func TestFairPriceSource_SubscribePriceStream(t *testing.T) {
 		}
 	)
 
-	mockTimeNow := time.Unix(119, 0)
-	go func() {
-		time.Sleep(2 * time.Second)
-
-		mockTimeNow = time.Unix(121, 0)
-	}()
-
-	mockTimeNowFunc := func() time.Time {
-		return mockTimeNow
-	}
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
 
-	fairPriceSource := fairpricesource.New(mockAlgorithm, mockStorage, mockSubscribers, mockTimeNowFunc)
+	fairPriceSource := fairpricesource.New(mockAlgorithm, mockStorage, mockSubscribers, time.Minute, mockClock.Now)
 
 	tickerPrices, tickerErrors := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
 
@@ -181,3 +245,1413 @@ func TestFairPriceSource_SubscribePriceStream(t *testing.T) {
 		assert.Equal(t, mockTimeslot, types.Timeslot(resultTickerPrices[0].Time.Unix()))
 	}
 }
+
+func TestFairPriceSource_SubscribeCandleStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockSourceID1 = types.SourceID("source_1")
+		mockSourceID2 = types.SourceID("source_2")
+
+		mockSource = newMockSource()
+
+		mockStorage = &PriceStorageMock{
+			GetPricesFunc: func(
+				ticker types.Ticker,
+				timeslot types.Timeslot,
+			) map[types.SourceID][]types.TickerPrice {
+				return map[types.SourceID][]types.TickerPrice{
+					mockSourceID1: {
+						{Ticker: mockTicker, Time: time.Unix(61, 0), Price: "2.0", Volume: "1"},
+						{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "4.0", Volume: "1"},
+						{Ticker: mockTicker, Time: time.Unix(63, 0), Price: "3.0"},
+					},
+					mockSourceID2: {
+						{Ticker: mockTicker, Time: time.Unix(61, 0), Price: "5.0", Volume: "3"},
+					},
+				}
+			},
+			RemovePricesFunc: func(
+				ticker types.Ticker,
+				timeslot types.Timeslot,
+			) {
+			},
+		}
+
+		mockAlgorithm = &PriceAlgorithmMock{
+			CalculatePriceFunc: func(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+				var sum decimal.Decimal
+
+				for _, price := range prices {
+					sum = sum.Add(price.Price)
+				}
+
+				return sum.Div(decimal.NewFromInt(int64(len(prices)))), nil
+			},
+		}
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			mockSourceID1: mockSource,
+			mockSourceID2: mockSource,
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(mockAlgorithm, mockStorage, mockSubscribers, time.Minute, mockClock.Now)
+
+	tickerCandles, _ := fairPriceSource.SubscribeCandleStream(ctx, mockTicker)
+
+	var resultTickerCandles []types.TickerCandles
+
+	for candles := range tickerCandles {
+		resultTickerCandles = append(resultTickerCandles, candles)
+
+		cancel()
+	}
+
+	if assert.Equal(t, 1, len(resultTickerCandles)) {
+		assert.Equal(t, types.Candle{
+			Open:   "2",
+			High:   "4",
+			Low:    "2",
+			Close:  "3",
+			Volume: "2",
+		}, resultTickerCandles[0].Sources[mockSourceID1])
+
+		assert.Equal(t, types.Candle{
+			Open:   "3.5",
+			High:   "4.5",
+			Low:    "3.5",
+			Close:  "4",
+			Volume: "5",
+		}, resultTickerCandles[0].Fair)
+	}
+}
+
+func TestFairPriceSource_SubscribeCandleStream_WithPriceStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockSourceTicks = map[types.SourceID][]types.TickerPrice{
+			"source_1": {
+				{Ticker: mockTicker, Time: time.Unix(61, 0), Price: "99", Volume: "1"},
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "100", Volume: "1"},
+			},
+			"source_2": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "102", Volume: "3"},
+			},
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		memstorage.New(),
+		newMockSubscribers(mockSourceTicks),
+		time.Minute,
+		mockClock.Now,
+	)
+
+	// the streams share the ticks of the timeslot, neither of them removes them before the other one
+	tickerCandles, _ := fairPriceSource.SubscribeCandleStream(ctx, mockTicker)
+	tickerPrices, _ := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+	candles, ok := <-tickerCandles
+	if !assert.True(t, ok) {
+		return
+	}
+
+	tickerPrice, ok := <-tickerPrices
+	if !assert.True(t, ok) {
+		return
+	}
+
+	cancel()
+
+	assert.Equal(t, "101", tickerPrice.Price)
+	assert.Equal(t, types.Candle{
+		Open:   "99",
+		High:   "100",
+		Low:    "99",
+		Close:  "100",
+		Volume: "2",
+	}, candles.Sources["source_1"])
+	assert.Equal(t, "3", candles.Sources["source_2"].Volume)
+}
+
+func TestFairPriceSource_SubscribePriceStream_GracePeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockTimeslot = types.Timeslot(60)
+
+		// the second tick moves the source past the end of the timeslot
+		mockSource = newMockSource(
+			types.TickerPrice{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "1.0"},
+			types.TickerPrice{Ticker: mockTicker, Time: time.Unix(121, 0), Price: "1.0"},
+		)
+
+		mockStorage = &PriceStorageMock{
+			AddPriceFunc: func(
+				ticker types.Ticker,
+				timeslot types.Timeslot,
+				sourceID types.SourceID,
+				price types.TickerPrice,
+			) {
+			},
+			GetPricesFunc: func(
+				ticker types.Ticker,
+				timeslot types.Timeslot,
+			) map[types.SourceID][]types.TickerPrice {
+				return map[types.SourceID][]types.TickerPrice{
+					"source_1": {{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "1.0"}},
+				}
+			},
+			RemovePricesFunc: func(
+				ticker types.Ticker,
+				timeslot types.Timeslot,
+			) {
+			},
+		}
+
+		mockAlgorithm = &PriceAlgorithmMock{
+			CalculatePriceFunc: func(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+				return decimal.NewFromInt(1), nil
+			},
+		}
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			"source_1": mockSource,
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		mockAlgorithm,
+		mockStorage,
+		mockSubscribers,
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithGracePeriod(time.Hour),
+	)
+
+	tickerPrices, _ := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+	var resultTickerPrices []types.TickerPrice
+
+	for tickerPrice := range tickerPrices {
+		resultTickerPrices = append(resultTickerPrices, tickerPrice)
+
+		cancel()
+	}
+
+	if assert.Equal(t, 1, len(resultTickerPrices)) {
+		assert.Equal(t, mockTimeslot, types.Timeslot(resultTickerPrices[0].Time.Unix()))
+	}
+}
+
+func TestFairPriceSource_SubscribePriceStream_SlowStorage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		storage = memstorage.New()
+
+		// the tick is still being stored when the timeslot is due to be published
+		mockStorage = &PriceStorageMock{
+			AddPriceFunc: func(
+				ticker types.Ticker,
+				timeslot types.Timeslot,
+				sourceID types.SourceID,
+				price types.TickerPrice,
+			) {
+				time.Sleep(1500 * time.Millisecond)
+
+				storage.AddPrice(ticker, timeslot, sourceID, price)
+			},
+			GetPricesFunc:    storage.GetPrices,
+			RemovePricesFunc: storage.RemovePrices,
+		}
+
+		mockSubscribers = newMockSubscribers(map[types.SourceID][]types.TickerPrice{
+			"source_1": {{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "1.0"}},
+		})
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		mockStorage,
+		mockSubscribers,
+		time.Minute,
+		mockClock.Now,
+	)
+
+	tickerPrices, tickerErrors := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+	// the timeslot is published once the tick is stored instead of being skipped without prices
+	select {
+	case tickerPrice, ok := <-tickerPrices:
+		if assert.True(t, ok) {
+			assert.Equal(t, "1", tickerPrice.Price)
+		}
+
+	case tickerError := <-tickerErrors:
+		assert.NoError(t, tickerError)
+	}
+
+	cancel()
+}
+
+func TestFairPriceSource_SubscribePriceStreams(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker1 = types.Ticker("ticker_1")
+		mockTicker2 = types.Ticker("ticker_2")
+
+		mockMultiSource = newMockMultiSource(
+			types.TickerPrice{Ticker: mockTicker1, Time: time.Unix(62, 0), Price: "1.0"},
+			types.TickerPrice{Ticker: mockTicker2, Time: time.Unix(62, 0), Price: "10.0"},
+		)
+
+		mockSingleSource = newMockSource(
+			types.TickerPrice{Ticker: mockTicker1, Time: time.Unix(63, 0), Price: "2.0"},
+			types.TickerPrice{Ticker: mockTicker2, Time: time.Unix(63, 0), Price: "20.0"},
+		)
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			"source_1": mockMultiSource,
+			"source_2": mockSingleSource,
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		memstorage.New(),
+		mockSubscribers,
+		time.Minute,
+		mockClock.Now,
+	)
+
+	tickerPrices, _ := fairPriceSource.SubscribePriceStreams(ctx, []types.Ticker{mockTicker1, mockTicker2})
+
+	resultPrices := make(map[types.Ticker]string)
+
+	for tickerPrice := range tickerPrices {
+		resultPrices[tickerPrice.Ticker] = tickerPrice.Price
+
+		if len(resultPrices) == 2 {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, map[types.Ticker]string{
+		mockTicker1: "1.5",
+		mockTicker2: "15",
+	}, resultPrices)
+	assert.Equal(t, 1, len(mockMultiSource.SubscribePriceStreamsCalls()))
+	assert.Equal(t, 2, len(mockSingleSource.SubscribePriceStreamCalls()))
+}
+
+func TestFairPriceSource_SubscribePriceStream_Errors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker   = types.Ticker("ticker_1")
+		mockSourceID = types.SourceID("source_1")
+		mockError    = errors.New("connection reset")
+
+		mockSource = &PriceStreamSubscriberMock{
+			SubscribePriceStreamFunc: func(
+				ctx context.Context,
+				ticker types.Ticker,
+			) (
+				<-chan types.TickerPrice,
+				<-chan error,
+			) {
+				tickers := make(chan types.TickerPrice)
+				errors := make(chan error, 1)
+
+				errors <- mockError
+
+				close(tickers)
+				close(errors)
+
+				return tickers, errors
+			},
+		}
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			mockSourceID: mockSource,
+		}
+	)
+
+	fairPriceSource := fairpricesource.New(
+		&PriceAlgorithmMock{},
+		&PriceStorageMock{},
+		mockSubscribers,
+		time.Minute,
+		time.Now,
+	)
+
+	_, tickerErrors := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+	tickerError := <-tickerErrors
+
+	cancel()
+
+	var sourceError *fairpricesource.Error
+
+	if assert.ErrorAs(t, tickerError, &sourceError) {
+		assert.ErrorIs(t, tickerError, fairpricesource.ErrSourceDisconnected)
+		assert.ErrorIs(t, tickerError, mockError)
+		assert.Equal(t, mockSourceID, sourceError.SourceID)
+		assert.Equal(t, mockTicker, sourceError.Ticker)
+	}
+}
+
+func TestFairPriceSource_SubscribePriceStream_ReconnectPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker   = types.Ticker("ticker_1")
+		mockSourceID = types.SourceID("source_1")
+
+		mockSource = &PriceStreamSubscriberMock{
+			SubscribePriceStreamFunc: func(
+				ctx context.Context,
+				ticker types.Ticker,
+			) (
+				<-chan types.TickerPrice,
+				<-chan error,
+			) {
+				tickers := make(chan types.TickerPrice)
+				errors := make(chan error)
+
+				close(tickers)
+				close(errors)
+
+				return tickers, errors
+			},
+		}
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			mockSourceID: mockSource,
+		}
+	)
+
+	fairPriceSource := fairpricesource.New(
+		&PriceAlgorithmMock{},
+		&PriceStorageMock{},
+		mockSubscribers,
+		time.Minute,
+		time.Now,
+		fairpricesource.WithSourceReconnectPolicy(mockSourceID, fairpricesource.ReconnectPolicy{
+			InitialDelay: 10 * time.Millisecond,
+			Multiplier:   2,
+			MaxAttempts:  3,
+		}),
+	)
+
+	_, tickerErrors := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+	tickerError := <-tickerErrors
+
+	cancel()
+
+	assert.ErrorIs(t, tickerError, fairpricesource.ErrSourceFailed)
+	assert.Equal(t, 3, len(mockSource.SubscribePriceStreamCalls()))
+}
+
+func TestFairPriceSource_Health(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTickers   = []types.Ticker{"ticker_1", "ticker_2"}
+		mockSourceID1 = types.SourceID("source_1")
+		mockSourceID2 = types.SourceID("source_2")
+
+		// the first source keeps sending ticks
+		mockSource1 = &PriceStreamSubscriberMock{
+			SubscribePriceStreamFunc: func(
+				ctx context.Context,
+				ticker types.Ticker,
+			) (
+				<-chan types.TickerPrice,
+				<-chan error,
+			) {
+				tickers := make(chan types.TickerPrice)
+				errors := make(chan error)
+
+				go func() {
+					defer close(tickers)
+					defer close(errors)
+
+					for {
+						select {
+						case tickers <- types.TickerPrice{Ticker: ticker, Time: time.Now(), Price: "1.0"}:
+						case <-ctx.Done():
+							return
+						}
+
+						select {
+						case <-time.After(50 * time.Millisecond):
+						case <-ctx.Done():
+							return
+						}
+					}
+				}()
+
+				return tickers, errors
+			},
+		}
+
+		// the second source sends a single tick and goes silent
+		mockSource2 = &PriceStreamSubscriberMock{
+			SubscribePriceStreamFunc: func(
+				ctx context.Context,
+				ticker types.Ticker,
+			) (
+				<-chan types.TickerPrice,
+				<-chan error,
+			) {
+				tickers := make(chan types.TickerPrice, 1)
+				errors := make(chan error)
+
+				tickers <- types.TickerPrice{Ticker: ticker, Time: time.Now(), Price: "2.0"}
+
+				go func() {
+					<-ctx.Done()
+					close(tickers)
+					close(errors)
+				}()
+
+				return tickers, errors
+			},
+		}
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			mockSourceID1: mockSource1,
+			mockSourceID2: mockSource2,
+		}
+	)
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		memstorage.New(),
+		mockSubscribers,
+		time.Second,
+		time.Now,
+		fairpricesource.WithStaleAfter(500*time.Millisecond),
+	)
+
+	tickerPrices, tickerErrors := fairPriceSource.SubscribePriceStreams(ctx, mockTickers)
+
+	go func() {
+		for range tickerPrices {
+		}
+	}()
+
+	// every ticker is told about the stale source
+	staleErrors := make(map[types.Ticker]*fairpricesource.Error)
+
+	for tickerError := range tickerErrors {
+		var staleError *fairpricesource.Error
+
+		if errors.As(tickerError, &staleError) && errors.Is(tickerError, fairpricesource.ErrSourceStale) {
+			staleErrors[staleError.Ticker] = staleError
+		}
+
+		if len(staleErrors) == len(mockTickers) {
+			break
+		}
+	}
+
+	health := fairPriceSource.Health()
+
+	// the silent source has no ticks in the timeslots after the one with its last tick
+	assert.Eventually(t, func() bool {
+		return fairPriceSource.Health()[mockSourceID2].TicksPerSlot[mockTickers[0]] == 0
+	}, 3*time.Second, 50*time.Millisecond)
+
+	cancel()
+
+	for _, ticker := range mockTickers {
+		if assert.NotNil(t, staleErrors[ticker], ticker) {
+			assert.Equal(t, mockSourceID2, staleErrors[ticker].SourceID)
+		}
+	}
+
+	assert.False(t, health[mockSourceID1].Stale)
+	assert.Equal(t, fairpricesource.StateConnected, health[mockSourceID1].State)
+	assert.Len(t, health[mockSourceID1].Subscriptions, len(mockTickers))
+	assert.True(t, health[mockSourceID2].Stale)
+	assert.False(t, health[mockSourceID2].LastTickTime.IsZero())
+}
+
+func TestFairPriceSource_Health_Subscriptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker1  = types.Ticker("ticker_1")
+		mockTicker2  = types.Ticker("ticker_2")
+		mockSourceID = types.SourceID("source_1")
+
+		// the source streams the first ticker and fails to subscribe to the second one
+		mockSource = &PriceStreamSubscriberMock{
+			SubscribePriceStreamFunc: func(
+				ctx context.Context,
+				ticker types.Ticker,
+			) (
+				<-chan types.TickerPrice,
+				<-chan error,
+			) {
+				tickers := make(chan types.TickerPrice)
+				errors := make(chan error)
+
+				if ticker == mockTicker2 {
+					close(tickers)
+					close(errors)
+
+					return tickers, errors
+				}
+
+				go func() {
+					<-ctx.Done()
+					close(tickers)
+					close(errors)
+				}()
+
+				return tickers, errors
+			},
+		}
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			mockSourceID: mockSource,
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(61, 0))
+
+	fairPriceSource := fairpricesource.New(
+		&PriceAlgorithmMock{},
+		&PriceStorageMock{},
+		mockSubscribers,
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithReconnectPolicy(fairpricesource.ReconnectPolicy{
+			InitialDelay: 10 * time.Millisecond,
+			Multiplier:   2,
+			MaxAttempts:  2,
+		}),
+	)
+
+	_, tickerErrors := fairPriceSource.SubscribePriceStreams(ctx, []types.Ticker{mockTicker1, mockTicker2})
+
+	tickerError := <-tickerErrors
+
+	health := fairPriceSource.Health()
+
+	cancel()
+
+	assert.ErrorIs(t, tickerError, fairpricesource.ErrSourceFailed)
+
+	// the failed subscription does not override the state of the connected one
+	assert.Equal(t, fairpricesource.StateConnected, health[mockSourceID].State)
+	assert.Equal(t, 1, health[mockSourceID].Reconnects)
+	assert.Equal(t, []fairpricesource.SubscriptionHealth{
+		{
+			Tickers:   []types.Ticker{mockTicker1},
+			State:     fairpricesource.StateConnected,
+			StartedAt: time.Unix(61, 0),
+		},
+		{
+			Tickers:    []types.Ticker{mockTicker2},
+			State:      fairpricesource.StateFailed,
+			StartedAt:  time.Unix(61, 0),
+			Reconnects: 1,
+		},
+	}, health[mockSourceID].Subscriptions)
+}
+
+func TestFairPriceSource_Health_LateSubscription(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		// the second source has not sent any ticks yet
+		mockSubscribers = newMockSubscribers(map[types.SourceID][]types.TickerPrice{
+			"source_1": {{Ticker: mockTicker, Time: time.Unix(1019, 0), Price: "100"}},
+			"source_2": nil,
+		})
+	)
+
+	mockClock := newMockClock(time.Unix(61, 0))
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		memstorage.New(),
+		mockSubscribers,
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithStaleAfter(30*time.Second),
+	)
+
+	// the ticker is subscribed to long after the source has been created
+	mockClock.Set(time.Unix(1019, 0))
+	mockClock.SetAfter(time.Unix(1021, 0))
+
+	tickerPrices, tickerErrors := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+	tickerPrice, ok := <-tickerPrices
+
+	cancel()
+
+	var resultErrors []error
+
+	for tickerError := range tickerErrors {
+		resultErrors = append(resultErrors, tickerError)
+	}
+
+	if assert.True(t, ok) {
+		assert.Equal(t, "100", tickerPrice.Price)
+	}
+
+	for _, tickerError := range resultErrors {
+		assert.NotErrorIs(t, tickerError, fairpricesource.ErrSourceStale)
+	}
+
+	assert.False(t, fairPriceSource.Health()["source_2"].Stale)
+}
+
+func TestFairPriceSource_SubscribePriceStream_Quorum(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockSource = newMockSource(types.TickerPrice{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "1.0"})
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			"source_1": mockSource,
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		memstorage.New(),
+		mockSubscribers,
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithQuorum(fairpricesource.Quorum{
+			MinSources: 2,
+			Action:     fairpricesource.QuorumLowConfidence,
+		}),
+	)
+
+	tickerPrices, _ := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+	var resultTickerPrices []types.TickerPrice
+
+	for tickerPrice := range tickerPrices {
+		resultTickerPrices = append(resultTickerPrices, tickerPrice)
+
+		cancel()
+	}
+
+	if assert.Equal(t, 1, len(resultTickerPrices)) {
+		assert.Equal(t, "1", resultTickerPrices[0].Price)
+		assert.Equal(t, types.PriceStatusLowConfidence, resultTickerPrices[0].Status)
+	}
+}
+
+func TestFairPriceSource_SubscribeFairBarStream_QuorumWeight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockSourceTicks = map[types.SourceID][]types.TickerPrice{
+			"source_1": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "100", Bid: "99", Ask: "101"},
+			},
+			// no quote, has no weight in the spread-weighted price
+			"source_2": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "150"},
+			},
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		quotealgorithm.NewSpreadWeighted(),
+		memstorage.New(),
+		newMockSubscribers(mockSourceTicks),
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithQuorum(fairpricesource.Quorum{
+			MinSources: 2,
+			Action:     fairpricesource.QuorumLowConfidence,
+		}),
+	)
+
+	fairBars, _ := fairPriceSource.SubscribeFairBarStream(ctx, mockTicker)
+
+	var resultFairBars []types.FairBar
+
+	for fairBar := range fairBars {
+		resultFairBars = append(resultFairBars, fairBar)
+
+		cancel()
+	}
+
+	if assert.Equal(t, 1, len(resultFairBars)) {
+		assert.Equal(t, "100", resultFairBars[0].Price)
+		assert.Equal(t, types.PriceStatusLowConfidence, resultFairBars[0].Status)
+		assert.Equal(t, []types.SourceID{"source_1"}, resultFairBars[0].Contributors)
+		assert.Equal(t, map[types.SourceID]string{"source_2": "no weight in the fair price"}, resultFairBars[0].Excluded)
+	}
+}
+
+func TestFairPriceSource_SubscribePriceStream_OrderPolicy(t *testing.T) {
+	var (
+		mockTicker   = types.Ticker("ticker_1")
+		mockSourceID = types.SourceID("source_1")
+
+		// the second tick is older than the first one
+		mockSource = newMockSource(
+			types.TickerPrice{Ticker: mockTicker, Time: time.Unix(63, 0), Price: "1.0"},
+			types.TickerPrice{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "2.0"},
+		)
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			mockSourceID: mockSource,
+		}
+	)
+
+	tests := []struct {
+		name           string
+		policy         fairpricesource.OrderPolicy
+		expectedPrices []string
+	}{
+		{name: "drop", policy: fairpricesource.OrderDrop, expectedPrices: []string{"1.0"}},
+		{name: "accept", policy: fairpricesource.OrderAccept, expectedPrices: []string{"1.0", "2.0"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			storedPrices := make(chan string, 2)
+
+			mockStorage := &PriceStorageMock{
+				AddPriceFunc: func(
+					ticker types.Ticker,
+					timeslot types.Timeslot,
+					sourceID types.SourceID,
+					price types.TickerPrice,
+				) {
+					storedPrices <- price.Price
+				},
+			}
+
+			fairPriceSource := fairpricesource.New(
+				&PriceAlgorithmMock{},
+				mockStorage,
+				mockSubscribers,
+				time.Minute,
+				func() time.Time { return time.Unix(61, 0) },
+				fairpricesource.WithOrderPolicy(test.policy),
+			)
+
+			_, tickerErrors := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+			assert.ErrorIs(t, <-tickerErrors, fairpricesource.ErrOutOfOrder)
+
+			cancel()
+
+			for range tickerErrors {
+			}
+
+			close(storedPrices)
+
+			var resultPrices []string
+
+			for price := range storedPrices {
+				resultPrices = append(resultPrices, price)
+			}
+
+			assert.Equal(t, test.expectedPrices, resultPrices)
+			assert.Equal(t, 1, fairPriceSource.Health()[mockSourceID].OrderViolations)
+		})
+	}
+}
+
+func TestFairPriceSource_SubscribePriceStream_TickerRegistry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockSource = newMockSource(
+			types.TickerPrice{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "1.239"},
+			types.TickerPrice{Ticker: mockTicker, Time: time.Unix(63, 0), Price: "100"},
+			types.TickerPrice{Ticker: mockTicker, Time: time.Unix(121, 0), Price: "1.239"},
+		)
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			"source_1": mockSource,
+		}
+
+		mockRegistry = tickerregistry.New()
+	)
+
+	err := mockRegistry.Register(tickerregistry.TickerInfo{
+		Ticker:    mockTicker,
+		Precision: 2,
+		TickSize:  decimal.MustParse("0.05"),
+		MaxPrice:  decimal.NewFromInt(10),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		memstorage.New(),
+		mockSubscribers,
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithTickerRegistry(mockRegistry),
+		fairpricesource.WithRoundingMode(decimal.RoundDown),
+	)
+
+	tickerPrices, tickerErrors := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+	var resultTickerPrices []types.TickerPrice
+
+	for tickerPrice := range tickerPrices {
+		resultTickerPrices = append(resultTickerPrices, tickerPrice)
+
+		cancel()
+	}
+
+	// the price above the max price is dropped, the last valid price is rounded down to the tick size
+	if assert.Equal(t, 1, len(resultTickerPrices)) {
+		assert.Equal(t, "1.20", resultTickerPrices[0].Price)
+	}
+
+	var invalidPrices int
+
+	for err := range tickerErrors {
+		if errors.Is(err, fairpricesource.ErrInvalidPrice) {
+			invalidPrices++
+		}
+	}
+
+	assert.Equal(t, 1, invalidPrices)
+}
+
+func TestFairPriceSource_SubscribePriceStreams_SymbolMap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker1 = types.Ticker("BTC_USD")
+		mockTicker2 = types.Ticker("ETH_USD")
+
+		// the source names tickers differently and reports prices under its own symbols
+		mockMultiSource = newMockMultiSource(
+			types.TickerPrice{Ticker: "XBT/USD", Time: time.Unix(62, 0), Price: "1.0"},
+			types.TickerPrice{Ticker: "ETH/USD", Time: time.Unix(62, 0), Price: "10.0"},
+			types.TickerPrice{Ticker: "XRP/USD", Time: time.Unix(62, 0), Price: "100.0"},
+		)
+
+		mockSingleSource = newMockSource(
+			types.TickerPrice{Ticker: "tBTCUSD", Time: time.Unix(63, 0), Price: "2.0"},
+			types.TickerPrice{Ticker: mockTicker2, Time: time.Unix(63, 0), Price: "20.0"},
+		)
+
+		mockSubscribers = map[types.SourceID]types.PriceStreamSubscriber{
+			"source_1": mockMultiSource,
+			"source_2": mockSingleSource,
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		memstorage.New(),
+		mockSubscribers,
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithSymbolMap("source_1", fairpricesource.SymbolMap{
+			mockTicker1: "XBT/USD",
+			mockTicker2: "ETH/USD",
+		}),
+		fairpricesource.WithSymbolMap("source_2", fairpricesource.SymbolMap{
+			mockTicker1: "tBTCUSD",
+		}),
+	)
+
+	tickerPrices, _ := fairPriceSource.SubscribePriceStreams(ctx, []types.Ticker{mockTicker1, mockTicker2})
+
+	resultPrices := make(map[types.Ticker]string)
+
+	for tickerPrice := range tickerPrices {
+		resultPrices[tickerPrice.Ticker] = tickerPrice.Price
+
+		if len(resultPrices) == 2 {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, map[types.Ticker]string{
+		mockTicker1: "1.5",
+		mockTicker2: "15",
+	}, resultPrices)
+
+	if assert.Equal(t, 1, len(mockMultiSource.SubscribePriceStreamsCalls())) {
+		assert.Equal(t, []types.Ticker{"XBT/USD", "ETH/USD"}, mockMultiSource.SubscribePriceStreamsCalls()[0].Tickers)
+	}
+
+	var singleSourceTickers []types.Ticker
+
+	for _, call := range mockSingleSource.SubscribePriceStreamCalls() {
+		singleSourceTickers = append(singleSourceTickers, call.Ticker)
+	}
+
+	assert.ElementsMatch(t, []types.Ticker{"tBTCUSD", mockTicker2}, singleSourceTickers)
+}
+
+func TestNew_InvalidSymbolMap(t *testing.T) {
+	assert.Panics(t, func() {
+		fairpricesource.New(nil, nil, nil, time.Minute, time.Now,
+			fairpricesource.WithSymbolMap("source_1", fairpricesource.SymbolMap{
+				"BTC_USD": "BTCUSD",
+				"XBT_USD": "BTCUSD",
+			}),
+		)
+	})
+}
+
+func TestFairPriceSource_SubscribePriceStream_FairPriceObserver(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker1 = types.Ticker("ticker_1")
+		mockTicker2 = types.Ticker("ticker_2")
+
+		// the first source does not quote the second ticker
+		mockSubscribers = newMockSubscribers(map[types.SourceID][]types.TickerPrice{
+			"source_1": {
+				{Ticker: mockTicker1, Time: time.Unix(62, 0), Price: "100"},
+			},
+			"source_2": {
+				{Ticker: mockTicker1, Time: time.Unix(62, 0), Price: "110"},
+				{Ticker: mockTicker2, Time: time.Unix(62, 0), Price: "10"},
+			},
+		})
+
+		// the filter rejects the second source if there are other ones, it is still scored by the algorithm
+		mockFilter = &PriceFilterMock{
+			FilterPricesFunc: func(
+				prices map[types.SourceID]types.SourcePrice,
+			) (map[types.SourceID]types.SourcePrice, map[types.SourceID]types.Rejection) {
+				if len(prices) == 1 {
+					return prices, nil
+				}
+
+				return map[types.SourceID]types.SourcePrice{"source_1": prices["source_1"]},
+					map[types.SourceID]types.Rejection{"source_2": {Price: prices["source_2"].Price}}
+			},
+		}
+
+		algorithm = reliabilityalgorithm.New(reliabilityalgorithm.DefaultConfig)
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		algorithm,
+		memstorage.New(),
+		mockSubscribers,
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithPriceFilter(mockFilter),
+	)
+
+	tickerPrices, _ := fairPriceSource.SubscribePriceStreams(ctx, []types.Ticker{mockTicker1, mockTicker2})
+
+	resultTickerPrices := make(map[types.Ticker]types.TickerPrice)
+
+	for tickerPrice := range tickerPrices {
+		resultTickerPrices[tickerPrice.Ticker] = tickerPrice
+
+		if len(resultTickerPrices) == 2 {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, "100", resultTickerPrices[mockTicker1].Price)
+	assert.Equal(t, "10", resultTickerPrices[mockTicker2].Price)
+
+	scores := algorithm.TickerScores(mockTicker1)
+
+	// the first source is not penalized for missing the second ticker
+	assert.Equal(t, 1, scores["source_1"].Timeslots)
+	assert.Equal(t, 0, scores["source_1"].Missed)
+	assert.Equal(t, 1, scores["source_2"].Timeslots)
+	assert.Equal(t, 1.0, scores["source_1"].Score)
+	assert.Less(t, scores["source_2"].Score, 1.0)
+
+	assert.NotContains(t, algorithm.TickerScores(mockTicker2), types.SourceID("source_1"))
+}
+
+func TestFairPriceSource_SubscribePriceStream_CarryForward(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		// the second source does not send prices in the second timeslot
+		mockSourceTicks = map[types.SourceID][]types.TickerPrice{
+			"source_1": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "1.0"},
+				{Ticker: mockTicker, Time: time.Unix(130, 0), Price: "5.0"},
+			},
+			"source_2": {
+				{Ticker: mockTicker, Time: time.Unix(63, 0), Price: "3.0"},
+			},
+		}
+
+		mockAlgorithm = &PriceAlgorithmMock{
+			CalculatePriceFunc: func(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+				return averagealgorithm.New().CalculatePrice(prices)
+			},
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(181, 0))
+
+	fairPriceSource := fairpricesource.New(
+		mockAlgorithm,
+		memstorage.New(),
+		newMockSubscribers(mockSourceTicks),
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithCarryForward(2*time.Minute),
+	)
+
+	tickerPrices, _ := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+
+	var resultPrices []string
+
+	for tickerPrice := range tickerPrices {
+		resultPrices = append(resultPrices, tickerPrice.Price)
+
+		if len(resultPrices) == 2 {
+			cancel()
+		}
+	}
+
+	// the price of the second source is carried into the second timeslot
+	assert.Equal(t, []string{"2", "4"}, resultPrices)
+
+	calls := mockAlgorithm.CalculatePriceCalls()
+
+	if assert.NotEmpty(t, calls) {
+		lastPrices := calls[len(calls)-1].Prices
+
+		assert.False(t, lastPrices["source_1"].Carried)
+		assert.True(t, lastPrices["source_2"].Carried)
+	}
+}
+
+func TestFairPriceSource_SubscribeFairBarStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockSourceTicks = map[types.SourceID][]types.TickerPrice{
+			"source_1": {
+				{Ticker: mockTicker, Time: time.Unix(61, 0), Price: "99"},
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "100"},
+			},
+			"source_2": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "102"},
+			},
+			"source_3": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "150"},
+			},
+			"source_4": nil,
+		}
+
+		mockFilter = &PriceFilterMock{
+			FilterPricesFunc: func(
+				prices map[types.SourceID]types.SourcePrice,
+			) (map[types.SourceID]types.SourcePrice, map[types.SourceID]types.Rejection) {
+				accepted := make(map[types.SourceID]types.SourcePrice)
+
+				for sourceID, price := range prices {
+					if sourceID != "source_3" {
+						accepted[sourceID] = price
+					}
+				}
+
+				return accepted, map[types.SourceID]types.Rejection{"source_3": {Reason: "outlier"}}
+			},
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		memstorage.New(),
+		newMockSubscribers(mockSourceTicks),
+		time.Minute,
+		mockClock.Now,
+		fairpricesource.WithPriceFilter(mockFilter),
+	)
+
+	fairBars, _ := fairPriceSource.SubscribeFairBarStream(ctx, mockTicker)
+
+	var resultFairBars []types.FairBar
+
+	for fairBar := range fairBars {
+		resultFairBars = append(resultFairBars, fairBar)
+
+		cancel()
+	}
+
+	if !assert.Equal(t, 1, len(resultFairBars)) {
+		return
+	}
+
+	fairBar := resultFairBars[0]
+
+	assert.Equal(t, mockTicker, fairBar.Ticker)
+	assert.Equal(t, types.Timeslot(60), types.Timeslot(fairBar.Time.Unix()))
+	assert.Equal(t, "101", fairBar.Price)
+	assert.Equal(t, types.PriceStatusOK, fairBar.Status)
+	assert.Equal(t, []types.SourceID{"source_1", "source_2"}, fairBar.Contributors)
+	assert.Empty(t, fairBar.Carried)
+	assert.Equal(t, map[types.SourceID]string{
+		"source_3": "rejected by filter: outlier",
+		"source_4": "no prices in the timeslot",
+	}, fairBar.Excluded)
+	assert.Equal(t, "100", fairBar.Min)
+	assert.Equal(t, "102", fairBar.Max)
+	assert.Equal(t, "1", fairBar.StdDev)
+	assert.Equal(t, map[types.SourceID]int{"source_1": 2, "source_2": 1, "source_3": 1}, fairBar.TickCounts)
+
+	// half of the sources contribute, the standard deviation is about 1% of the price
+	assert.InDelta(t, 0.5/(1+(1.0/101)/0.001), fairBar.Confidence, 1e-9)
+
+	assert.Equal(t, types.TickerPrice{
+		Ticker: mockTicker,
+		Time:   fairBar.Time,
+		Price:  "101",
+	}, fairBar.TickerPrice())
+}
+
+func TestFairPriceSource_SubscribeFairBarStream_SharedPipeline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockSourceTicks = map[types.SourceID][]types.TickerPrice{
+			"source_1": {{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "100"}},
+			"source_2": {{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "102"}},
+		}
+	)
+
+	mockClock := newMockClock(time.Unix(119, 0))
+	mockClock.SetAfter(time.Unix(121, 0))
+
+	fairPriceSource := fairpricesource.New(
+		averagealgorithm.New(),
+		memstorage.New(),
+		newMockSubscribers(mockSourceTicks),
+		time.Minute,
+		mockClock.Now,
+	)
+
+	// both streams are served by a single pipeline, so ticks are stored and removed once
+	tickerPrices, _ := fairPriceSource.SubscribePriceStream(ctx, mockTicker)
+	fairBars, _ := fairPriceSource.SubscribeFairBarStream(ctx, mockTicker)
+
+	tickerPrice, ok := <-tickerPrices
+	if !assert.True(t, ok) {
+		return
+	}
+
+	fairBar, ok := <-fairBars
+	if !assert.True(t, ok) {
+		return
+	}
+
+	cancel()
+
+	assert.Equal(t, "101", tickerPrice.Price)
+	assert.Equal(t, fairBar.TickerPrice(), tickerPrice)
+	assert.Equal(t, map[types.SourceID]int{"source_1": 1, "source_2": 1}, fairBar.TickCounts)
+}
+
+func TestFairPriceSource_SubscribeFairBarStream_Quotes(t *testing.T) {
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockSourceTicks = map[types.SourceID][]types.TickerPrice{
+			// quotes only, the mid is used as the price
+			"source_1": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Bid: "99", Ask: "101"},
+			},
+			"source_2": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "102", Bid: "101", Ask: "102.5"},
+			},
+			// no quotes, contributes to the fair price only
+			"source_3": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "101"},
+			},
+			// crossed quote is dropped
+			"source_4": {
+				{Ticker: mockTicker, Time: time.Unix(62, 0), Price: "103", Bid: "104", Ask: "103"},
+			},
+		}
+	)
+
+	subscribe := func(options ...fairpricesource.Option) ([]types.FairBar, int) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mockClock := newMockClock(time.Unix(119, 0))
+		mockClock.SetAfter(time.Unix(121, 0))
+
+		fairPriceSource := fairpricesource.New(
+			averagealgorithm.New(),
+			memstorage.New(),
+			newMockSubscribers(mockSourceTicks),
+			time.Minute,
+			mockClock.Now,
+			options...,
+		)
+
+		fairBars, fairBarErrors := fairPriceSource.SubscribeFairBarStream(ctx, mockTicker)
+
+		var resultFairBars []types.FairBar
+
+		for fairBar := range fairBars {
+			resultFairBars = append(resultFairBars, fairBar)
+
+			cancel()
+		}
+
+		var parseErrors int
+
+		for err := range fairBarErrors {
+			if errors.Is(err, fairpricesource.ErrParsePrice) {
+				parseErrors++
+			}
+		}
+
+		return resultFairBars, parseErrors
+	}
+
+	t.Run("price algorithm", func(t *testing.T) {
+		fairBars, parseErrors := subscribe()
+
+		assert.Equal(t, 1, parseErrors)
+
+		if !assert.Equal(t, 1, len(fairBars)) {
+			return
+		}
+
+		fairBar := fairBars[0]
+
+		// (100 + 102 + 101) / 3, bids and asks of quoting sources are averaged separately
+		assert.Equal(t, "101", fairBar.Price)
+		assert.Equal(t, "100", fairBar.Bid)
+		assert.Equal(t, "101.75", fairBar.Ask)
+		assert.Equal(t, "100.875", fairBar.Mid)
+		assert.Equal(t, "1.75", fairBar.Spread)
+
+		assert.Equal(t, types.TickerPrice{
+			Ticker: mockTicker,
+			Time:   fairBar.Time,
+			Price:  "101",
+			Bid:    "100",
+			Ask:    "101.75",
+		}, fairBar.TickerPrice())
+	})
+
+	t.Run("quote algorithm", func(t *testing.T) {
+		fairBars, _ := subscribe(fairpricesource.WithQuoteAlgorithm(quotealgorithm.NewBestQuote()))
+
+		if !assert.Equal(t, 1, len(fairBars)) {
+			return
+		}
+
+		fairBar := fairBars[0]
+
+		assert.Equal(t, "101", fairBar.Price)
+		assert.Equal(t, "101", fairBar.Bid)
+		assert.Equal(t, "101", fairBar.Ask)
+		assert.Equal(t, "101", fairBar.Mid)
+		assert.Equal(t, "0", fairBar.Spread)
+	})
+}
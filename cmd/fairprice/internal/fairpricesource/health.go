@@ -0,0 +1,294 @@
+package fairpricesource
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+// ConnectionState is the state of the connection to a source.
+type ConnectionState int
+
+const (
+	StateConnecting ConnectionState = iota
+	StateConnected
+	StateDisconnected
+	StateFailed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// connectionStateRanks orders connection states from the best one.
+var connectionStateRanks = map[ConnectionState]int{
+	StateConnected:    0,
+	StateConnecting:   1,
+	StateDisconnected: 2,
+	StateFailed:       3,
+}
+
+// SourceHealth is a snapshot of the health of a source.
+type SourceHealth struct {
+	State           ConnectionState      // the best state of subscriptions, connected if any subscription is connected
+	Subscriptions   []SubscriptionHealth // active upstream subscriptions to the source, ordered by tickers
+	LastTickTime    time.Time            // time of the last tick reported by the source
+	LastReceivedAt  time.Time            // time the last tick was received at
+	Reconnects      int                  // number of reconnects of all subscriptions since the start
+	Errors          int                  // number of errors since the start
+	OrderViolations int                  // number of ticks delivered out of time order since the start
+	TicksPerSlot    map[types.Ticker]int // number of ticks in the last published timeslot of every ticker
+	Stale           bool                 // no ticks have been received for too long
+}
+
+// SubscriptionHealth is a snapshot of the health of a single upstream subscription to a source.
+// A source has a subscription per ticker unless it supports subscribing to several tickers at once.
+type SubscriptionHealth struct {
+	Tickers    []types.Ticker
+	State      ConnectionState
+	StartedAt  time.Time
+	Reconnects int // number of reconnects of the subscription
+}
+
+// healthTracker tracks the health of every source.
+type healthTracker struct {
+	mutex         sync.RWMutex
+	sources       map[types.SourceID]*SourceHealth
+	subscriptions map[types.SourceID]map[*SubscriptionHealth]struct{}
+	staleAfter    time.Duration
+}
+
+// newHealthTracker creates a new initialized instance of healthTracker.
+func newHealthTracker(sourceIDs []types.SourceID, staleAfter time.Duration) *healthTracker {
+	sources := make(map[types.SourceID]*SourceHealth, len(sourceIDs))
+	subscriptions := make(map[types.SourceID]map[*SubscriptionHealth]struct{}, len(sourceIDs))
+
+	for _, sourceID := range sourceIDs {
+		sources[sourceID] = &SourceHealth{
+			State:        StateDisconnected,
+			TicksPerSlot: make(map[types.Ticker]int),
+		}
+
+		subscriptions[sourceID] = make(map[*SubscriptionHealth]struct{})
+	}
+
+	return &healthTracker{
+		sources:       sources,
+		subscriptions: subscriptions,
+		staleAfter:    staleAfter,
+	}
+}
+
+// AddSubscription starts tracking an upstream subscription to the source.
+func (h *healthTracker) AddSubscription(
+	sourceID types.SourceID,
+	tickers []types.Ticker,
+	startedAt time.Time,
+) *SubscriptionHealth {
+	subscription := &SubscriptionHealth{
+		Tickers:   append([]types.Ticker(nil), tickers...),
+		State:     StateDisconnected,
+		StartedAt: startedAt,
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if subscriptions, ok := h.subscriptions[sourceID]; ok {
+		subscriptions[subscription] = struct{}{}
+	}
+
+	return subscription
+}
+
+// RemoveSubscription stops tracking the subscription once its consumer has left.
+func (h *healthTracker) RemoveSubscription(sourceID types.SourceID, subscription *SubscriptionHealth) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.subscriptions[sourceID], subscription)
+}
+
+// Connecting records an attempt to subscribe to the source.
+func (h *healthTracker) Connecting(sourceID types.SourceID, subscription *SubscriptionHealth, reconnect bool) {
+	h.update(sourceID, func(health *SourceHealth) {
+		subscription.State = StateConnecting
+
+		if reconnect {
+			subscription.Reconnects++
+			health.Reconnects++
+		}
+	})
+}
+
+// Connected records a successful subscription to the source.
+func (h *healthTracker) Connected(sourceID types.SourceID, subscription *SubscriptionHealth) {
+	h.update(sourceID, func(health *SourceHealth) {
+		subscription.State = StateConnected
+	})
+}
+
+// Disconnected records the end of a subscription to the source.
+func (h *healthTracker) Disconnected(sourceID types.SourceID, subscription *SubscriptionHealth) {
+	h.update(sourceID, func(health *SourceHealth) {
+		subscription.State = StateDisconnected
+	})
+}
+
+// Failed records that the subscription to the source has been given up.
+func (h *healthTracker) Failed(sourceID types.SourceID, subscription *SubscriptionHealth) {
+	h.update(sourceID, func(health *SourceHealth) {
+		subscription.State = StateFailed
+	})
+}
+
+// Tick records a tick received from the source.
+func (h *healthTracker) Tick(sourceID types.SourceID, tickTime time.Time, receivedAt time.Time) {
+	h.update(sourceID, func(health *SourceHealth) {
+		health.LastTickTime = tickTime
+		health.LastReceivedAt = receivedAt
+		health.Stale = false
+	})
+}
+
+// Error records an error related to the source.
+func (h *healthTracker) Error(sourceID types.SourceID) {
+	h.update(sourceID, func(health *SourceHealth) {
+		health.Errors++
+	})
+}
+
+// OrderViolation records a tick delivered by the source out of time order.
+func (h *healthTracker) OrderViolation(sourceID types.SourceID) {
+	h.update(sourceID, func(health *SourceHealth) {
+		health.OrderViolations++
+	})
+}
+
+// SlotTicks records the number of ticks of the source in the last published timeslot of the ticker.
+func (h *healthTracker) SlotTicks(sourceID types.SourceID, ticker types.Ticker, ticks int) {
+	h.update(sourceID, func(health *SourceHealth) {
+		health.TicksPerSlot[ticker] = ticks
+	})
+}
+
+// CheckStale marks sources without ticks for too long as stale and returns all stale sources.
+// The time without ticks is counted from the start of the oldest subscription at the latest,
+// sources without subscriptions are not checked.
+func (h *healthTracker) CheckStale(now time.Time) map[types.SourceID]struct{} {
+	if h.staleAfter <= 0 {
+		return nil
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	stale := make(map[types.SourceID]struct{})
+
+	for sourceID, health := range h.sources {
+		var subscribedAt time.Time
+
+		for subscription := range h.subscriptions[sourceID] {
+			if subscribedAt.IsZero() || subscription.StartedAt.Before(subscribedAt) {
+				subscribedAt = subscription.StartedAt
+			}
+		}
+
+		if subscribedAt.IsZero() {
+			continue
+		}
+
+		lastSeen := health.LastReceivedAt
+		if lastSeen.Before(subscribedAt) {
+			lastSeen = subscribedAt
+		}
+
+		health.Stale = now.Sub(lastSeen) > h.staleAfter
+
+		if health.Stale {
+			stale[sourceID] = struct{}{}
+		}
+	}
+
+	return stale
+}
+
+// Snapshot returns the health of every source.
+func (h *healthTracker) Snapshot() map[types.SourceID]SourceHealth {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	snapshot := make(map[types.SourceID]SourceHealth, len(h.sources))
+
+	for sourceID, health := range h.sources {
+		s := *health
+
+		s.TicksPerSlot = make(map[types.Ticker]int, len(health.TicksPerSlot))
+
+		for ticker, ticks := range health.TicksPerSlot {
+			s.TicksPerSlot[ticker] = ticks
+		}
+
+		s.Subscriptions = make([]SubscriptionHealth, 0, len(h.subscriptions[sourceID]))
+
+		for subscription := range h.subscriptions[sourceID] {
+			c := *subscription
+
+			c.Tickers = append([]types.Ticker(nil), subscription.Tickers...)
+
+			s.Subscriptions = append(s.Subscriptions, c)
+		}
+
+		sort.Slice(s.Subscriptions, func(i, j int) bool {
+			return s.Subscriptions[i].Tickers[0] < s.Subscriptions[j].Tickers[0]
+		})
+
+		s.State = bestState(s.Subscriptions)
+
+		snapshot[sourceID] = s
+	}
+
+	return snapshot
+}
+
+func (h *healthTracker) update(sourceID types.SourceID, update func(health *SourceHealth)) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	health, ok := h.sources[sourceID]
+	if !ok {
+		return
+	}
+
+	update(health)
+}
+
+// bestState returns the best state of the subscriptions, a source without subscriptions is disconnected.
+func bestState(subscriptions []SubscriptionHealth) ConnectionState {
+	if len(subscriptions) == 0 {
+		return StateDisconnected
+	}
+
+	best := subscriptions[0].State
+
+	for _, subscription := range subscriptions[1:] {
+		if connectionStateRanks[subscription.State] < connectionStateRanks[best] {
+			best = subscription.State
+		}
+	}
+
+	return best
+}
@@ -5,72 +5,73 @@ package fairpricesource_test
 
 import (
 	"sync"
+	"time"
 	"tickerprice/cmd/fairprice/internal/fairpricesource"
 	"tickerprice/cmd/fairprice/internal/types"
 )
 
-// Ensure, that PriceAlgorithmMock does implement fairpricesource.PriceAlgorithm.
+// Ensure, that PriceFilterMock does implement fairpricesource.PriceFilter.
 // If this is not the case, regenerate this file with moq.
-var _ fairpricesource.PriceAlgorithm = &PriceAlgorithmMock{}
+var _ fairpricesource.PriceFilter = &PriceFilterMock{}
 
-// PriceAlgorithmMock is a mock implementation of fairpricesource.PriceAlgorithm.
+// PriceFilterMock is a mock implementation of fairpricesource.PriceFilter.
 //
-// 	func TestSomethingThatUsesPriceAlgorithm(t *testing.T) {
+// 	func TestSomethingThatUsesPriceFilter(t *testing.T) {
 //
-// 		// make and configure a mocked fairpricesource.PriceAlgorithm
-// 		mockedPriceAlgorithm := &PriceAlgorithmMock{
-// 			CalculatePriceFunc: func(prices map[types.SourceID]float64) (float64, error) {
-// 				panic("mock out the CalculatePrice method")
+// 		// make and configure a mocked fairpricesource.PriceFilter
+// 		mockedPriceFilter := &PriceFilterMock{
+// 			FilterPricesFunc: func(prices map[types.SourceID]types.SourcePrice) (map[types.SourceID]types.SourcePrice, map[types.SourceID]types.Rejection) {
+// 				panic("mock out the FilterPrices method")
 // 			},
 // 		}
 //
-// 		// use mockedPriceAlgorithm in code that requires fairpricesource.PriceAlgorithm
+// 		// use mockedPriceFilter in code that requires fairpricesource.PriceFilter
 // 		// and then make assertions.
 //
 // 	}
-type PriceAlgorithmMock struct {
-	// CalculatePriceFunc mocks the CalculatePrice method.
-	CalculatePriceFunc func(prices map[types.SourceID]float64) (float64, error)
+type PriceFilterMock struct {
+	// FilterPricesFunc mocks the FilterPrices method.
+	FilterPricesFunc func(prices map[types.SourceID]types.SourcePrice) (map[types.SourceID]types.SourcePrice, map[types.SourceID]types.Rejection)
 
 	// calls tracks calls to the methods.
 	calls struct {
-		// CalculatePrice holds details about calls to the CalculatePrice method.
-		CalculatePrice []struct {
+		// FilterPrices holds details about calls to the FilterPrices method.
+		FilterPrices []struct {
 			// Prices is the prices argument value.
-			Prices map[types.SourceID]float64
+			Prices map[types.SourceID]types.SourcePrice
 		}
 	}
-	lockCalculatePrice sync.RWMutex
+	lockFilterPrices sync.RWMutex
 }
 
-// CalculatePrice calls CalculatePriceFunc.
-func (mock *PriceAlgorithmMock) CalculatePrice(prices map[types.SourceID]float64) (float64, error) {
-	if mock.CalculatePriceFunc == nil {
-		panic("PriceAlgorithmMock.CalculatePriceFunc: method is nil but PriceAlgorithm.CalculatePrice was just called")
+// FilterPrices calls FilterPricesFunc.
+func (mock *PriceFilterMock) FilterPrices(prices map[types.SourceID]types.SourcePrice) (map[types.SourceID]types.SourcePrice, map[types.SourceID]types.Rejection) {
+	if mock.FilterPricesFunc == nil {
+		panic("PriceFilterMock.FilterPricesFunc: method is nil but PriceFilter.FilterPrices was just called")
 	}
 	callInfo := struct {
-		Prices map[types.SourceID]float64
+		Prices map[types.SourceID]types.SourcePrice
 	}{
 		Prices: prices,
 	}
-	mock.lockCalculatePrice.Lock()
-	mock.calls.CalculatePrice = append(mock.calls.CalculatePrice, callInfo)
-	mock.lockCalculatePrice.Unlock()
-	return mock.CalculatePriceFunc(prices)
+	mock.lockFilterPrices.Lock()
+	mock.calls.FilterPrices = append(mock.calls.FilterPrices, callInfo)
+	mock.lockFilterPrices.Unlock()
+	return mock.FilterPricesFunc(prices)
 }
 
-// CalculatePriceCalls gets all the calls that were made to CalculatePrice.
+// FilterPricesCalls gets all the calls that were made to FilterPrices.
 // Check the length with:
-//     len(mockedPriceAlgorithm.CalculatePriceCalls())
-func (mock *PriceAlgorithmMock) CalculatePriceCalls() []struct {
-	Prices map[types.SourceID]float64
+//     len(mockedPriceFilter.FilterPricesCalls())
+func (mock *PriceFilterMock) FilterPricesCalls() []struct {
+	Prices map[types.SourceID]types.SourcePrice
 } {
 	var calls []struct {
-		Prices map[types.SourceID]float64
+		Prices map[types.SourceID]types.SourcePrice
 	}
-	mock.lockCalculatePrice.RLock()
-	calls = mock.calls.CalculatePrice
-	mock.lockCalculatePrice.RUnlock()
+	mock.lockFilterPrices.RLock()
+	calls = mock.calls.FilterPrices
+	mock.lockFilterPrices.RUnlock()
 	return calls
 }
 
@@ -84,10 +85,10 @@ var _ fairpricesource.PriceStorage = &PriceStorageMock{}
 //
 // 		// make and configure a mocked fairpricesource.PriceStorage
 // 		mockedPriceStorage := &PriceStorageMock{
-// 			AddPriceFunc: func(ticker types.Ticker, timeslot types.Timeslot, sourceID types.SourceID, price string)  {
+// 			AddPriceFunc: func(ticker types.Ticker, timeslot types.Timeslot, sourceID types.SourceID, price types.TickerPrice)  {
 // 				panic("mock out the AddPrice method")
 // 			},
-// 			GetPricesFunc: func(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID]string {
+// 			GetPricesFunc: func(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID][]types.TickerPrice {
 // 				panic("mock out the GetPrices method")
 // 			},
 // 			RemovePricesFunc: func(ticker types.Ticker, timeslot types.Timeslot)  {
@@ -101,10 +102,10 @@ var _ fairpricesource.PriceStorage = &PriceStorageMock{}
 // 	}
 type PriceStorageMock struct {
 	// AddPriceFunc mocks the AddPrice method.
-	AddPriceFunc func(ticker types.Ticker, timeslot types.Timeslot, sourceID types.SourceID, price string)
+	AddPriceFunc func(ticker types.Ticker, timeslot types.Timeslot, sourceID types.SourceID, price types.TickerPrice)
 
 	// GetPricesFunc mocks the GetPrices method.
-	GetPricesFunc func(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID]string
+	GetPricesFunc func(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID][]types.TickerPrice
 
 	// RemovePricesFunc mocks the RemovePrices method.
 	RemovePricesFunc func(ticker types.Ticker, timeslot types.Timeslot)
@@ -120,7 +121,7 @@ type PriceStorageMock struct {
 			// SourceID is the sourceID argument value.
 			SourceID types.SourceID
 			// Price is the price argument value.
-			Price string
+			Price types.TickerPrice
 		}
 		// GetPrices holds details about calls to the GetPrices method.
 		GetPrices []struct {
@@ -143,7 +144,7 @@ type PriceStorageMock struct {
 }
 
 // AddPrice calls AddPriceFunc.
-func (mock *PriceStorageMock) AddPrice(ticker types.Ticker, timeslot types.Timeslot, sourceID types.SourceID, price string) {
+func (mock *PriceStorageMock) AddPrice(ticker types.Ticker, timeslot types.Timeslot, sourceID types.SourceID, price types.TickerPrice) {
 	if mock.AddPriceFunc == nil {
 		panic("PriceStorageMock.AddPriceFunc: method is nil but PriceStorage.AddPrice was just called")
 	}
@@ -151,7 +152,7 @@ func (mock *PriceStorageMock) AddPrice(ticker types.Ticker, timeslot types.Times
 		Ticker   types.Ticker
 		Timeslot types.Timeslot
 		SourceID types.SourceID
-		Price    string
+		Price    types.TickerPrice
 	}{
 		Ticker:   ticker,
 		Timeslot: timeslot,
@@ -171,13 +172,13 @@ func (mock *PriceStorageMock) AddPriceCalls() []struct {
 	Ticker   types.Ticker
 	Timeslot types.Timeslot
 	SourceID types.SourceID
-	Price    string
+	Price    types.TickerPrice
 } {
 	var calls []struct {
 		Ticker   types.Ticker
 		Timeslot types.Timeslot
 		SourceID types.SourceID
-		Price    string
+		Price    types.TickerPrice
 	}
 	mock.lockAddPrice.RLock()
 	calls = mock.calls.AddPrice
@@ -186,7 +187,7 @@ func (mock *PriceStorageMock) AddPriceCalls() []struct {
 }
 
 // GetPrices calls GetPricesFunc.
-func (mock *PriceStorageMock) GetPrices(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID]string {
+func (mock *PriceStorageMock) GetPrices(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID][]types.TickerPrice {
 	if mock.GetPricesFunc == nil {
 		panic("PriceStorageMock.GetPricesFunc: method is nil but PriceStorage.GetPrices was just called")
 	}
@@ -254,3 +255,80 @@ func (mock *PriceStorageMock) RemovePricesCalls() []struct {
 	mock.lockRemovePrices.RUnlock()
 	return calls
 }
+
+// Ensure, that TickAggregatorMock does implement fairpricesource.TickAggregator.
+// If this is not the case, regenerate this file with moq.
+var _ fairpricesource.TickAggregator = &TickAggregatorMock{}
+
+// TickAggregatorMock is a mock implementation of fairpricesource.TickAggregator.
+//
+// 	func TestSomethingThatUsesTickAggregator(t *testing.T) {
+//
+// 		// make and configure a mocked fairpricesource.TickAggregator
+// 		mockedTickAggregator := &TickAggregatorMock{
+// 			AggregateTicksFunc: func(start time.Time, end time.Time, ticks []types.SourcePrice) (types.SourcePrice, error) {
+// 				panic("mock out the AggregateTicks method")
+// 			},
+// 		}
+//
+// 		// use mockedTickAggregator in code that requires fairpricesource.TickAggregator
+// 		// and then make assertions.
+//
+// 	}
+type TickAggregatorMock struct {
+	// AggregateTicksFunc mocks the AggregateTicks method.
+	AggregateTicksFunc func(start time.Time, end time.Time, ticks []types.SourcePrice) (types.SourcePrice, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AggregateTicks holds details about calls to the AggregateTicks method.
+		AggregateTicks []struct {
+			// Start is the start argument value.
+			Start time.Time
+			// End is the end argument value.
+			End time.Time
+			// Ticks is the ticks argument value.
+			Ticks []types.SourcePrice
+		}
+	}
+	lockAggregateTicks sync.RWMutex
+}
+
+// AggregateTicks calls AggregateTicksFunc.
+func (mock *TickAggregatorMock) AggregateTicks(start time.Time, end time.Time, ticks []types.SourcePrice) (types.SourcePrice, error) {
+	if mock.AggregateTicksFunc == nil {
+		panic("TickAggregatorMock.AggregateTicksFunc: method is nil but TickAggregator.AggregateTicks was just called")
+	}
+	callInfo := struct {
+		Start time.Time
+		End   time.Time
+		Ticks []types.SourcePrice
+	}{
+		Start: start,
+		End:   end,
+		Ticks: ticks,
+	}
+	mock.lockAggregateTicks.Lock()
+	mock.calls.AggregateTicks = append(mock.calls.AggregateTicks, callInfo)
+	mock.lockAggregateTicks.Unlock()
+	return mock.AggregateTicksFunc(start, end, ticks)
+}
+
+// AggregateTicksCalls gets all the calls that were made to AggregateTicks.
+// Check the length with:
+//     len(mockedTickAggregator.AggregateTicksCalls())
+func (mock *TickAggregatorMock) AggregateTicksCalls() []struct {
+	Start time.Time
+	End   time.Time
+	Ticks []types.SourcePrice
+} {
+	var calls []struct {
+		Start time.Time
+		End   time.Time
+		Ticks []types.SourcePrice
+	}
+	mock.lockAggregateTicks.RLock()
+	calls = mock.calls.AggregateTicks
+	mock.lockAggregateTicks.RUnlock()
+	return calls
+}
@@ -7,8 +7,194 @@ import (
 	"context"
 	"sync"
 	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
 )
 
+// Ensure, that MultiPriceStreamSubscriberMock does implement types.MultiPriceStreamSubscriber.
+// If this is not the case, regenerate this file with moq.
+var _ types.MultiPriceStreamSubscriber = &MultiPriceStreamSubscriberMock{}
+
+// MultiPriceStreamSubscriberMock is a mock implementation of types.MultiPriceStreamSubscriber.
+//
+// 	func TestSomethingThatUsesMultiPriceStreamSubscriber(t *testing.T) {
+//
+// 		// make and configure a mocked types.MultiPriceStreamSubscriber
+// 		mockedMultiPriceStreamSubscriber := &MultiPriceStreamSubscriberMock{
+// 			SubscribePriceStreamFunc: func(contextMoqParam context.Context, ticker types.Ticker) (<-chan types.TickerPrice, <-chan error) {
+// 				panic("mock out the SubscribePriceStream method")
+// 			},
+// 			SubscribePriceStreamsFunc: func(contextMoqParam context.Context, tickers []types.Ticker) (<-chan types.TickerPrice, <-chan error) {
+// 				panic("mock out the SubscribePriceStreams method")
+// 			},
+// 		}
+//
+// 		// use mockedMultiPriceStreamSubscriber in code that requires types.MultiPriceStreamSubscriber
+// 		// and then make assertions.
+//
+// 	}
+type MultiPriceStreamSubscriberMock struct {
+	// SubscribePriceStreamFunc mocks the SubscribePriceStream method.
+	SubscribePriceStreamFunc func(contextMoqParam context.Context, ticker types.Ticker) (<-chan types.TickerPrice, <-chan error)
+
+	// SubscribePriceStreamsFunc mocks the SubscribePriceStreams method.
+	SubscribePriceStreamsFunc func(contextMoqParam context.Context, tickers []types.Ticker) (<-chan types.TickerPrice, <-chan error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// SubscribePriceStream holds details about calls to the SubscribePriceStream method.
+		SubscribePriceStream []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// Ticker is the ticker argument value.
+			Ticker types.Ticker
+		}
+		// SubscribePriceStreams holds details about calls to the SubscribePriceStreams method.
+		SubscribePriceStreams []struct {
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// Tickers is the tickers argument value.
+			Tickers []types.Ticker
+		}
+	}
+	lockSubscribePriceStream  sync.RWMutex
+	lockSubscribePriceStreams sync.RWMutex
+}
+
+// SubscribePriceStream calls SubscribePriceStreamFunc.
+func (mock *MultiPriceStreamSubscriberMock) SubscribePriceStream(contextMoqParam context.Context, ticker types.Ticker) (<-chan types.TickerPrice, <-chan error) {
+	if mock.SubscribePriceStreamFunc == nil {
+		panic("MultiPriceStreamSubscriberMock.SubscribePriceStreamFunc: method is nil but MultiPriceStreamSubscriber.SubscribePriceStream was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		Ticker          types.Ticker
+	}{
+		ContextMoqParam: contextMoqParam,
+		Ticker:          ticker,
+	}
+	mock.lockSubscribePriceStream.Lock()
+	mock.calls.SubscribePriceStream = append(mock.calls.SubscribePriceStream, callInfo)
+	mock.lockSubscribePriceStream.Unlock()
+	return mock.SubscribePriceStreamFunc(contextMoqParam, ticker)
+}
+
+// SubscribePriceStreamCalls gets all the calls that were made to SubscribePriceStream.
+// Check the length with:
+//     len(mockedMultiPriceStreamSubscriber.SubscribePriceStreamCalls())
+func (mock *MultiPriceStreamSubscriberMock) SubscribePriceStreamCalls() []struct {
+	ContextMoqParam context.Context
+	Ticker          types.Ticker
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		Ticker          types.Ticker
+	}
+	mock.lockSubscribePriceStream.RLock()
+	calls = mock.calls.SubscribePriceStream
+	mock.lockSubscribePriceStream.RUnlock()
+	return calls
+}
+
+// SubscribePriceStreams calls SubscribePriceStreamsFunc.
+func (mock *MultiPriceStreamSubscriberMock) SubscribePriceStreams(contextMoqParam context.Context, tickers []types.Ticker) (<-chan types.TickerPrice, <-chan error) {
+	if mock.SubscribePriceStreamsFunc == nil {
+		panic("MultiPriceStreamSubscriberMock.SubscribePriceStreamsFunc: method is nil but MultiPriceStreamSubscriber.SubscribePriceStreams was just called")
+	}
+	callInfo := struct {
+		ContextMoqParam context.Context
+		Tickers         []types.Ticker
+	}{
+		ContextMoqParam: contextMoqParam,
+		Tickers:         tickers,
+	}
+	mock.lockSubscribePriceStreams.Lock()
+	mock.calls.SubscribePriceStreams = append(mock.calls.SubscribePriceStreams, callInfo)
+	mock.lockSubscribePriceStreams.Unlock()
+	return mock.SubscribePriceStreamsFunc(contextMoqParam, tickers)
+}
+
+// SubscribePriceStreamsCalls gets all the calls that were made to SubscribePriceStreams.
+// Check the length with:
+//     len(mockedMultiPriceStreamSubscriber.SubscribePriceStreamsCalls())
+func (mock *MultiPriceStreamSubscriberMock) SubscribePriceStreamsCalls() []struct {
+	ContextMoqParam context.Context
+	Tickers         []types.Ticker
+} {
+	var calls []struct {
+		ContextMoqParam context.Context
+		Tickers         []types.Ticker
+	}
+	mock.lockSubscribePriceStreams.RLock()
+	calls = mock.calls.SubscribePriceStreams
+	mock.lockSubscribePriceStreams.RUnlock()
+	return calls
+}
+
+// Ensure, that PriceAlgorithmMock does implement types.PriceAlgorithm.
+// If this is not the case, regenerate this file with moq.
+var _ types.PriceAlgorithm = &PriceAlgorithmMock{}
+
+// PriceAlgorithmMock is a mock implementation of types.PriceAlgorithm.
+//
+// 	func TestSomethingThatUsesPriceAlgorithm(t *testing.T) {
+//
+// 		// make and configure a mocked types.PriceAlgorithm
+// 		mockedPriceAlgorithm := &PriceAlgorithmMock{
+// 			CalculatePriceFunc: func(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+// 				panic("mock out the CalculatePrice method")
+// 			},
+// 		}
+//
+// 		// use mockedPriceAlgorithm in code that requires types.PriceAlgorithm
+// 		// and then make assertions.
+//
+// 	}
+type PriceAlgorithmMock struct {
+	// CalculatePriceFunc mocks the CalculatePrice method.
+	CalculatePriceFunc func(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CalculatePrice holds details about calls to the CalculatePrice method.
+		CalculatePrice []struct {
+			// Prices is the prices argument value.
+			Prices map[types.SourceID]types.SourcePrice
+		}
+	}
+	lockCalculatePrice sync.RWMutex
+}
+
+// CalculatePrice calls CalculatePriceFunc.
+func (mock *PriceAlgorithmMock) CalculatePrice(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+	if mock.CalculatePriceFunc == nil {
+		panic("PriceAlgorithmMock.CalculatePriceFunc: method is nil but PriceAlgorithm.CalculatePrice was just called")
+	}
+	callInfo := struct {
+		Prices map[types.SourceID]types.SourcePrice
+	}{
+		Prices: prices,
+	}
+	mock.lockCalculatePrice.Lock()
+	mock.calls.CalculatePrice = append(mock.calls.CalculatePrice, callInfo)
+	mock.lockCalculatePrice.Unlock()
+	return mock.CalculatePriceFunc(prices)
+}
+
+// CalculatePriceCalls gets all the calls that were made to CalculatePrice.
+// Check the length with:
+//     len(mockedPriceAlgorithm.CalculatePriceCalls())
+func (mock *PriceAlgorithmMock) CalculatePriceCalls() []struct {
+	Prices map[types.SourceID]types.SourcePrice
+} {
+	var calls []struct {
+		Prices map[types.SourceID]types.SourcePrice
+	}
+	mock.lockCalculatePrice.RLock()
+	calls = mock.calls.CalculatePrice
+	mock.lockCalculatePrice.RUnlock()
+	return calls
+}
+
 // Ensure, that PriceStreamSubscriberMock does implement types.PriceStreamSubscriber.
 // If this is not the case, regenerate this file with moq.
 var _ types.PriceStreamSubscriber = &PriceStreamSubscriberMock{}
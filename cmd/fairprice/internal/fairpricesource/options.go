@@ -0,0 +1,117 @@
+package fairpricesource
+
+import (
+	"time"
+
+	"tickerprice/cmd/fairprice/internal/tickerregistry"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// Option configures an optional behaviour of FairPriceSource.
+type Option func(*FairPriceSource)
+
+// WithTickAggregator sets an aggregator of prices reported by a source during a timeslot.
+// By default the last reported price is used.
+func WithTickAggregator(aggregator TickAggregator) Option {
+	return func(p *FairPriceSource) {
+		p.aggregator = aggregator
+	}
+}
+
+// WithGracePeriod sets how long to wait for late data after the end of a timeslot.
+// A timeslot is published earlier if every source has already delivered a tick after its end.
+// By default a timeslot is published as soon as it ends.
+func WithGracePeriod(gracePeriod time.Duration) Option {
+	return func(p *FairPriceSource) {
+		p.gracePeriod = gracePeriod
+	}
+}
+
+// WithReconnectPolicy sets the reconnect policy of sources without their own policy.
+// By default DefaultReconnectPolicy is used.
+func WithReconnectPolicy(policy ReconnectPolicy) Option {
+	return func(p *FairPriceSource) {
+		p.reconnectPolicy = policy
+	}
+}
+
+// WithSourceReconnectPolicy sets the reconnect policy of the source.
+func WithSourceReconnectPolicy(sourceID types.SourceID, policy ReconnectPolicy) Option {
+	return func(p *FairPriceSource) {
+		p.sourcePolicies[sourceID] = policy
+	}
+}
+
+// WithSymbolMap sets native symbols of tickers used by the source.
+// The source is subscribed with native symbols and its prices are reported under canonical tickers.
+func WithSymbolMap(sourceID types.SourceID, symbolMap SymbolMap) Option {
+	return func(p *FairPriceSource) {
+		p.symbolMaps[sourceID] = symbolMap
+	}
+}
+
+// WithStaleAfter sets how long a source may stay silent before its prices are excluded from the fair price.
+// By default sources never become stale.
+func WithStaleAfter(staleAfter time.Duration) Option {
+	return func(p *FairPriceSource) {
+		p.staleAfter = staleAfter
+	}
+}
+
+// WithQuorum sets the minimum amount of sources required to publish a fair price.
+// By default a fair price is published from any number of sources.
+func WithQuorum(quorum Quorum) Option {
+	return func(p *FairPriceSource) {
+		p.quorum = quorum
+	}
+}
+
+// WithOrderPolicy sets what is done with ticks delivered by a source out of time order.
+// By default such ticks are dropped.
+func WithOrderPolicy(policy OrderPolicy) Option {
+	return func(p *FairPriceSource) {
+		p.orderPolicy = policy
+	}
+}
+
+// WithCarryForward sets how long the last known price of a source is carried forward into timeslots
+// without its prices. Carried prices are flagged with SourcePrice.Carried so algorithms can down-weight them.
+// By default prices are not carried forward.
+func WithCarryForward(maxAge time.Duration) Option {
+	return func(p *FairPriceSource) {
+		p.carryMaxAge = maxAge
+	}
+}
+
+// WithPriceFilter sets a filter that runs before the fair price is calculated.
+func WithPriceFilter(filter PriceFilter) Option {
+	return func(p *FairPriceSource) {
+		p.filter = filter
+	}
+}
+
+// WithQuoteAlgorithm sets an algorithm calculating the fair bid and ask from quotes of sources.
+// By default the price algorithm is used if it implements QuoteAlgorithm, otherwise the fair bid
+// and the fair ask are calculated by the price algorithm from bids and asks separately.
+func WithQuoteAlgorithm(algorithm QuoteAlgorithm) Option {
+	return func(p *FairPriceSource) {
+		p.quoteAlgorithm = algorithm
+	}
+}
+
+// WithRoundingMode sets how fair prices are rounded to the tick size and precision of the ticker.
+// By default prices are rounded half away from zero.
+func WithRoundingMode(mode decimal.RoundingMode) Option {
+	return func(p *FairPriceSource) {
+		p.roundingMode = mode
+	}
+}
+
+// WithTickerRegistry sets the metadata of tickers used to validate prices of sources and to format fair prices.
+// By default prices of all tickers are accepted and rounded to 10 digits without trailing zeros.
+func WithTickerRegistry(registry *tickerregistry.Registry) Option {
+	return func(p *FairPriceSource) {
+		p.registry = registry
+	}
+}
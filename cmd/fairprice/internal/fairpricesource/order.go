@@ -0,0 +1,51 @@
+package fairpricesource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+// OrderPolicy is what is done with a tick which is not later than the previous tick of the source.
+type OrderPolicy int
+
+const (
+	// OrderDrop drops the tick.
+	OrderDrop OrderPolicy = iota
+	// OrderAccept stores the tick as if it was in order.
+	OrderAccept
+	// OrderDisconnect drops the tick and reconnects to the source.
+	OrderDisconnect
+)
+
+// checkOrder reports whether the tick is later than the previous tick of the source for the ticker.
+// Every violation is counted in the health of the source and reported as ErrOutOfOrder.
+func (p *FairPriceSource) checkOrder(
+	ctx context.Context,
+	sourceID types.SourceID,
+	ticker types.Ticker,
+	tickTime time.Time,
+	lastTickTimes map[types.Ticker]time.Time,
+	reporter *errorReporter,
+) bool {
+	lastTickTime, ok := lastTickTimes[ticker]
+	if !ok || tickTime.After(lastTickTime) {
+		lastTickTimes[ticker] = tickTime
+
+		return true
+	}
+
+	p.health.OrderViolation(sourceID)
+
+	reporter.Report(ctx, &Error{
+		Kind:     ErrOutOfOrder,
+		SourceID: sourceID,
+		Ticker:   ticker,
+		Timeslot: types.NewTimeslot(tickTime, p.timeslotDuration),
+		Err:      fmt.Errorf("tick time %v is not after the previous tick time %v", tickTime, lastTickTime),
+	})
+
+	return false
+}
@@ -0,0 +1,271 @@
+package fairpricesource
+
+import (
+	"context"
+	"sync"
+
+	"tickerprice/cmd/fairprice/internal/errorstream"
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+// pipeline calculates bars of a ticker once for all subscriptions to the ticker,
+// so every tick is stored and every timeslot is calculated once whatever streams are subscribed to.
+type pipeline struct {
+	ticker        types.Ticker
+	group         *pipelineGroup
+	subscriptions map[*subscription]struct{} // guarded by the mutex of FairPriceSource
+}
+
+// pipelineGroup is pipelines started together. They share upstream subscriptions of sources
+// supporting several tickers and stop together once none of them has subscriptions.
+type pipelineGroup struct {
+	pipelines []*pipeline
+	cancel    context.CancelFunc
+	stopping  bool          // guarded by the mutex of FairPriceSource
+	done      chan struct{} // closed when all goroutines of the group have finished
+}
+
+// subscription is a consumer of bars of its tickers.
+type subscription struct {
+	ctx    context.Context
+	bars   chan timeslotBar
+	mutex  sync.Mutex // guards sends of errors against closing the channel
+	errors chan error
+	closed bool
+}
+
+// newSubscription creates a new initialized instance of subscription.
+func newSubscription(ctx context.Context) *subscription {
+	return &subscription{
+		ctx:    ctx,
+		bars:   make(chan timeslotBar),
+		errors: make(chan error, errorstream.BufferSize),
+	}
+}
+
+// sendBar delivers the bar, it blocks until the consumer reads it or leaves.
+func (s *subscription) sendBar(ctx context.Context, bar timeslotBar) {
+	select {
+	case <-ctx.Done():
+	case <-s.ctx.Done():
+	case s.bars <- bar:
+	}
+}
+
+// sendError delivers the error or drops it if the consumer does not keep up with reading errors.
+func (s *subscription) sendError(ctx context.Context, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	errorstream.Report(ctx, s.errors, err)
+}
+
+func (s *subscription) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+
+	close(s.errors)
+}
+
+// attach adds the subscription to pipelines of the tickers and starts pipelines of tickers without them.
+// Pipelines which are stopping are waited for, so ticks of a ticker are never stored by two pipelines.
+// It reports false if the consumer has left while waiting.
+func (p *FairPriceSource) attach(s *subscription, tickers []types.Ticker) bool {
+	for {
+		p.mutex.Lock()
+
+		var stopping []chan struct{}
+
+		for _, ticker := range tickers {
+			if pipeline, ok := p.pipelines[ticker]; ok && pipeline.group.stopping {
+				stopping = append(stopping, pipeline.group.done)
+			}
+		}
+
+		if len(stopping) == 0 {
+			break
+		}
+
+		p.mutex.Unlock()
+
+		for _, done := range stopping {
+			select {
+			case <-s.ctx.Done():
+				return false
+
+			case <-done:
+			}
+		}
+	}
+
+	defer p.mutex.Unlock()
+
+	var started []types.Ticker
+
+	for _, ticker := range tickers {
+		if _, ok := p.pipelines[ticker]; !ok {
+			started = append(started, ticker)
+		}
+	}
+
+	if len(started) > 0 {
+		p.startGroup(started)
+	}
+
+	for _, ticker := range tickers {
+		p.pipelines[ticker].subscriptions[s] = struct{}{}
+	}
+
+	return true
+}
+
+// detach removes the subscription from pipelines of the tickers and stops groups left without subscriptions.
+// It returns channels closed once the stopped groups have finished.
+func (p *FairPriceSource) detach(s *subscription, tickers []types.Ticker) []chan struct{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	groups := make(map[*pipelineGroup]struct{})
+
+	for _, ticker := range tickers {
+		pipeline := p.pipelines[ticker]
+
+		delete(pipeline.subscriptions, s)
+
+		groups[pipeline.group] = struct{}{}
+	}
+
+	var stopped []chan struct{}
+
+	for group := range groups {
+		if group.stopping || group.subscribed() {
+			continue
+		}
+
+		group.stopping = true
+		group.cancel()
+
+		stopped = append(stopped, group.done)
+	}
+
+	return stopped
+}
+
+// subscribed reports whether any pipeline of the group has subscriptions, must be called with the mutex locked.
+func (g *pipelineGroup) subscribed() bool {
+	for _, pipeline := range g.pipelines {
+		if len(pipeline.subscriptions) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subscriptions returns the current subscriptions to the ticker, deliveries to them are made without the lock.
+func (p *FairPriceSource) subscriptions(ticker types.Ticker) []*subscription {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pipeline, ok := p.pipelines[ticker]
+	if !ok {
+		return nil
+	}
+
+	subscriptions := make([]*subscription, 0, len(pipeline.subscriptions))
+
+	for s := range pipeline.subscriptions {
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions
+}
+
+// startGroup starts pipelines of the tickers, must be called with the mutex locked.
+func (p *FairPriceSource) startGroup(tickers []types.Ticker) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	group := &pipelineGroup{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	for _, ticker := range tickers {
+		pipeline := &pipeline{
+			ticker:        ticker,
+			group:         group,
+			subscriptions: make(map[*subscription]struct{}),
+		}
+
+		group.pipelines = append(group.pipelines, pipeline)
+
+		p.pipelines[ticker] = pipeline
+	}
+
+	sourceIDs := make([]types.SourceID, 0, len(p.subscribers))
+
+	for sourceID := range p.subscribers {
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+
+	pending := types.NewTimeslot(p.timeNowFunc(), p.timeslotDuration)
+
+	progresses := make(map[types.Ticker]*progress, len(tickers))
+
+	for _, ticker := range tickers {
+		progresses[ticker] = newProgress(sourceIDs, pending)
+	}
+
+	reporter := newErrorReporter(p.health, p.subscriptions)
+
+	waitGroup := sync.WaitGroup{}
+
+	for sourceID, subscriber := range p.subscribers {
+		for _, subscriptionTickers := range splitSubscriptions(subscriber, tickers) {
+			waitGroup.Add(1)
+
+			go func(sourceID types.SourceID, subscriber types.PriceStreamSubscriber, tickers []types.Ticker) {
+				defer waitGroup.Done()
+
+				p.runSubscriber(ctx, tickers, sourceID, subscriber, progresses, reporter)
+			}(sourceID, subscriber, subscriptionTickers)
+		}
+	}
+
+	for _, ticker := range tickers {
+		waitGroup.Add(1)
+
+		go func(ticker types.Ticker) {
+			defer waitGroup.Done()
+
+			p.runPublisher(ctx, ticker, progresses[ticker], reporter, func(bar timeslotBar) bool {
+				for _, s := range p.subscriptions(ticker) {
+					s.sendBar(ctx, bar)
+				}
+
+				return ctx.Err() == nil
+			})
+		}(ticker)
+	}
+
+	go func() {
+		waitGroup.Wait()
+
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		for _, pipeline := range group.pipelines {
+			if p.pipelines[pipeline.ticker] == pipeline {
+				delete(p.pipelines, pipeline.ticker)
+			}
+		}
+
+		close(group.done)
+	}()
+}
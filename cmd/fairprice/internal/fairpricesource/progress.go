@@ -0,0 +1,80 @@
+package fairpricesource
+
+import (
+	"sync"
+	"time"
+
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+// progress tracks how far every source and the publisher of a subscription have advanced in time.
+type progress struct {
+	mutex      sync.RWMutex
+	watermarks map[types.SourceID]time.Time
+	pending    types.Timeslot // the earliest timeslot which is not finalized yet
+}
+
+// newProgress creates a new initialized instance of progress.
+func newProgress(sourceIDs []types.SourceID, pending types.Timeslot) *progress {
+	watermarks := make(map[types.SourceID]time.Time, len(sourceIDs))
+
+	for _, sourceID := range sourceIDs {
+		watermarks[sourceID] = time.Time{}
+	}
+
+	return &progress{
+		watermarks: watermarks,
+		pending:    pending,
+	}
+}
+
+// Advance moves the watermark of the source to the time of its latest tick and stores the tick.
+// It reports false and does not store the tick if the timeslot of the tick is already finalized.
+// The tick is stored under the lock, so the timeslot cannot be finalized between the check and the store.
+func (p *progress) Advance(sourceID types.SourceID, t time.Time, timeslot types.Timeslot, store func()) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if t.After(p.watermarks[sourceID]) {
+		p.watermarks[sourceID] = t
+	}
+
+	if timeslot < p.pending {
+		return false
+	}
+
+	store()
+
+	return true
+}
+
+// Passed reports whether every source has delivered a tick at or after the specified time.
+func (p *progress) Passed(t time.Time) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, watermark := range p.watermarks {
+		if watermark.Before(t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Pending returns the earliest timeslot which is not finalized yet.
+func (p *progress) Pending() types.Timeslot {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.pending
+}
+
+// Finalize marks the pending timeslot as finalized and moves to the next one.
+// Ticks of the finalized timeslot are not stored anymore once Finalize returns.
+func (p *progress) Finalize(next types.Timeslot) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.pending = next
+}
@@ -0,0 +1,121 @@
+package fairpricesource
+
+import (
+	"fmt"
+
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+// QuorumAction is what is published for a timeslot without quorum.
+type QuorumAction int
+
+const (
+	// QuorumSkip skips the timeslot and reports ErrInsufficientData.
+	QuorumSkip QuorumAction = iota
+	// QuorumCarryForward publishes the previous fair price with PriceStatusStale.
+	QuorumCarryForward
+	// QuorumLowConfidence publishes the fair price calculated from available sources with PriceStatusLowConfidence.
+	QuorumLowConfidence
+)
+
+// Quorum is the minimum amount of sources contributing to a fair price.
+type Quorum struct {
+	MinSources int          // minimum number of contributing sources
+	MinWeight  float64      // minimum total weight of contributing sources
+	Action     QuorumAction // what to publish without quorum
+}
+
+// PriceWeigher is implemented by algorithms which do not weight sources equally.
+// The weight of every source is 1 for other algorithms.
+type PriceWeigher interface {
+	// PriceWeight returns the weight of the price of the source in the fair price.
+	PriceWeight(sourceID types.SourceID, price types.SourcePrice) float64
+}
+
+// priceWeight returns the weight of the price of the source in the fair price.
+func priceWeight(algorithm types.PriceAlgorithm, sourceID types.SourceID, price types.SourcePrice) float64 {
+	if weigher, ok := algorithm.(PriceWeigher); ok {
+		return weigher.PriceWeight(sourceID, price)
+	}
+
+	return 1
+}
+
+// excludeUnweighted returns prices of sources with a positive weight in the fair price,
+// sources without weight do not contribute to the fair price and are excluded.
+func (p *FairPriceSource) excludeUnweighted(
+	algorithm types.PriceAlgorithm,
+	prices map[types.SourceID]types.SourcePrice,
+	excluded map[types.SourceID]string,
+) map[types.SourceID]types.SourcePrice {
+	weighted := make(map[types.SourceID]types.SourcePrice, len(prices))
+
+	for sourceID, price := range prices {
+		if priceWeight(algorithm, sourceID, price) > 0 {
+			weighted[sourceID] = price
+		} else {
+			excluded[sourceID] = "no weight in the fair price"
+		}
+	}
+
+	return weighted
+}
+
+// checkQuorum returns an error if the contributors do not meet the quorum.
+func (p *FairPriceSource) checkQuorum(algorithm types.PriceAlgorithm, contributors map[types.SourceID]types.SourcePrice) error {
+	var weight float64
+
+	for sourceID, price := range contributors {
+		weight += priceWeight(algorithm, sourceID, price)
+	}
+
+	if len(contributors) < p.quorum.MinSources || weight < p.quorum.MinWeight {
+		return fmt.Errorf("quorum is not met: %d sources with weight %v, required %d sources with weight %v",
+			len(contributors), weight, p.quorum.MinSources, p.quorum.MinWeight)
+	}
+
+	return nil
+}
+
+// carryForwardBar builds a bar of the timeslot from the previous fair price.
+// Prices of the timeslot do not contribute to the bar.
+func (p *FairPriceSource) carryForwardBar(
+	ticker types.Ticker,
+	timeslot types.Timeslot,
+	ticks map[types.SourceID][]types.SourcePrice,
+	contributors map[types.SourceID]types.SourcePrice,
+	excluded map[types.SourceID]string,
+	previous *timeslotBar,
+) timeslotBar {
+	flat := candle{
+		open:  previous.fairPrice,
+		high:  previous.fairPrice,
+		low:   previous.fairPrice,
+		close: previous.fairPrice,
+	}
+
+	for sourceID := range contributors {
+		excluded[sourceID] = "quorum is not met"
+	}
+
+	fairBar := p.buildFairBar(ticker, timeslot, previous.fairPrice, types.PriceStatusStale, nil, excluded, ticks)
+
+	// the previous fair quote is carried forward with the previous fair price
+	fairBar.Bid = previous.fairBar.Bid
+	fairBar.Ask = previous.fairBar.Ask
+	fairBar.Mid = previous.fairBar.Mid
+	fairBar.Spread = previous.fairBar.Spread
+
+	return timeslotBar{
+		fairPrice: previous.fairPrice,
+		fairBar:   fairBar,
+		price:     fairBar.TickerPrice(),
+		candles: types.TickerCandles{
+			Ticker:  ticker,
+			Time:    timeslot.ToTime(),
+			Fair:    p.formatCandle(ticker, flat),
+			Sources: p.formatCandles(ticker, buildCandles(ticks)),
+			Status:  types.PriceStatusStale,
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package fairpricesource
+
+import (
+	"fmt"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// calculateQuote calculates the fair quote from contributors with quotes.
+// It reports false if no contributor has a quote.
+func (p *FairPriceSource) calculateQuote(
+	algorithm types.PriceAlgorithm,
+	contributors map[types.SourceID]types.SourcePrice,
+) (types.Quote, bool, error) {
+	quoted := make(map[types.SourceID]types.SourcePrice, len(contributors))
+
+	for sourceID, price := range contributors {
+		if price.Quoted() {
+			quoted[sourceID] = price
+		}
+	}
+
+	if len(quoted) == 0 {
+		return types.Quote{}, false, nil
+	}
+
+	quoteAlgorithm := p.quoteAlgorithm
+
+	if quoteAlgorithm == nil {
+		if tickerQuoteAlgorithm, ok := algorithm.(QuoteAlgorithm); ok {
+			quoteAlgorithm = tickerQuoteAlgorithm
+		}
+	}
+
+	var (
+		quote types.Quote
+		err   error
+	)
+
+	if quoteAlgorithm != nil {
+		quote, err = quoteAlgorithm.CalculateQuote(quoted)
+	} else {
+		quote, err = p.calculateQuoteSides(algorithm, quoted)
+	}
+
+	if err != nil {
+		return types.Quote{}, false, err
+	}
+
+	if quote.Bid.Cmp(quote.Ask) > 0 {
+		return types.Quote{}, false, fmt.Errorf("bid %v is above ask %v", quote.Bid, quote.Ask)
+	}
+
+	return quote, true, nil
+}
+
+// calculateQuoteSides calculates the fair bid and the fair ask by the price algorithm from bids and asks separately.
+func (p *FairPriceSource) calculateQuoteSides(
+	algorithm types.PriceAlgorithm,
+	quoted map[types.SourceID]types.SourcePrice,
+) (types.Quote, error) {
+	var quote types.Quote
+
+	sides := []struct {
+		name  string
+		value func(q types.Quote) decimal.Decimal
+		fair  *decimal.Decimal
+	}{
+		{name: "bid", value: func(q types.Quote) decimal.Decimal { return q.Bid }, fair: &quote.Bid},
+		{name: "ask", value: func(q types.Quote) decimal.Decimal { return q.Ask }, fair: &quote.Ask},
+	}
+
+	for _, side := range sides {
+		prices := make(map[types.SourceID]types.SourcePrice, len(quoted))
+
+		for sourceID, price := range quoted {
+			prices[sourceID] = types.SourcePrice{
+				Time:    price.Time,
+				Price:   side.value(price.Quote()),
+				Volume:  price.Volume,
+				Bid:     price.Bid,
+				Ask:     price.Ask,
+				Carried: price.Carried,
+			}
+		}
+
+		price, err := algorithm.CalculatePrice(prices)
+		if err != nil {
+			return types.Quote{}, fmt.Errorf("%s: %w", side.name, err)
+		}
+
+		*side.fair = price
+	}
+
+	return quote, nil
+}
+
+// setQuote describes the fair quote in the bar.
+func (p *FairPriceSource) setQuote(bar *types.FairBar, quote types.Quote) {
+	bar.Bid = p.formatPrice(bar.Ticker, quote.Bid)
+	bar.Ask = p.formatPrice(bar.Ticker, quote.Ask)
+	bar.Mid = p.formatPrice(bar.Ticker, quote.Mid())
+	bar.Spread = p.formatPrice(bar.Ticker, quote.Spread())
+}
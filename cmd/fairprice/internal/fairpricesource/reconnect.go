@@ -0,0 +1,79 @@
+package fairpricesource
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ReconnectPolicy defines how a source is reconnected after its subscription has ended.
+type ReconnectPolicy struct {
+	InitialDelay time.Duration // delay before the first reconnect
+	Multiplier   float64       // growth of the delay after every failed attempt
+	MaxDelay     time.Duration // upper limit of the delay
+	Jitter       float64       // fraction of the delay randomly added or subtracted, from 0 to 1
+	MaxAttempts  int           // consecutive failed attempts before the source is given up, zero means unlimited
+	ResetAfter   time.Duration // a subscription streaming at least that long resets the backoff
+}
+
+// DefaultReconnectPolicy is the policy used for sources without a configured policy.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	InitialDelay: time.Second,
+	Multiplier:   2,
+	MaxDelay:     time.Minute,
+	Jitter:       0.2,
+	ResetAfter:   time.Minute,
+}
+
+// delay returns the delay before the reconnect following the specified number of consecutive failures.
+func (p ReconnectPolicy) delay(failures int) time.Duration {
+	delay := float64(p.InitialDelay)
+
+	if p.Multiplier > 1 && failures > 1 {
+		delay *= math.Pow(p.Multiplier, float64(failures-1))
+	}
+
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// reconnectWithBackoff calls connect again every time it returns until the context is done.
+// It returns false if the source has been given up according to the policy.
+func reconnectWithBackoff(ctx context.Context, policy ReconnectPolicy, connect func()) bool {
+	var failures int
+
+	// check context cancellation before repeat
+	for ctx.Err() == nil {
+		connectedAt := time.Now()
+
+		connect()
+
+		// a long enough subscription was healthy, start over
+		if policy.ResetAfter > 0 && time.Since(connectedAt) >= policy.ResetAfter {
+			failures = 0
+		}
+
+		failures++
+
+		if policy.MaxAttempts > 0 && failures >= policy.MaxAttempts {
+			return ctx.Err() != nil
+		}
+
+		// avoid the retries storm
+		select {
+		case <-time.After(policy.delay(failures)):
+		case <-ctx.Done():
+			return true
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,74 @@
+package fairpricesource
+
+import (
+	"fmt"
+
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+// SymbolMap maps canonical tickers to native symbols of a source. example: "BTC_USD" to "XBT/USD".
+// Tickers missing in the map are passed to the source unchanged.
+type SymbolMap map[types.Ticker]types.Ticker
+
+// symbols translates tickers of a subscription between canonical and native names of a source.
+type symbols struct {
+	native    map[types.Ticker]types.Ticker
+	canonical map[types.Ticker]types.Ticker
+}
+
+// newSymbols creates symbols of the tickers using the map of the source.
+func newSymbols(symbolMap SymbolMap, tickers []types.Ticker) (symbols, error) {
+	s := symbols{
+		native:    make(map[types.Ticker]types.Ticker, len(tickers)),
+		canonical: make(map[types.Ticker]types.Ticker, len(tickers)),
+	}
+
+	for _, ticker := range tickers {
+		symbol, ok := symbolMap[ticker]
+		if !ok {
+			symbol = ticker
+		}
+
+		if other, ok := s.canonical[symbol]; ok {
+			return symbols{}, fmt.Errorf("tickers %s and %s have the same symbol %s", other, ticker, symbol)
+		}
+
+		s.native[ticker] = symbol
+		s.canonical[symbol] = ticker
+	}
+
+	return s, nil
+}
+
+// validate returns an error if two tickers are mapped to the same native symbol.
+func (m SymbolMap) validate() error {
+	tickers := make(map[types.Ticker]types.Ticker, len(m))
+
+	for ticker, symbol := range m {
+		if other, ok := tickers[symbol]; ok {
+			return fmt.Errorf("tickers %s and %s have the same symbol %s", other, ticker, symbol)
+		}
+
+		tickers[symbol] = ticker
+	}
+
+	return nil
+}
+
+// Native returns native symbols of the tickers.
+func (s symbols) Native(tickers []types.Ticker) []types.Ticker {
+	native := make([]types.Ticker, 0, len(tickers))
+
+	for _, ticker := range tickers {
+		native = append(native, s.native[ticker])
+	}
+
+	return native
+}
+
+// Canonical returns the canonical ticker of the native symbol.
+func (s symbols) Canonical(symbol types.Ticker) (types.Ticker, bool) {
+	ticker, ok := s.canonical[symbol]
+
+	return ticker, ok
+}
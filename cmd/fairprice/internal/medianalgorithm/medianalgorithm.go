@@ -0,0 +1,41 @@
+package medianalgorithm
+
+import (
+	"fmt"
+	"sort"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// MedianAlgorithm is an algorithm that is resistant to a single source reporting a wrong price.
+type MedianAlgorithm struct{}
+
+// New creates a new initialized instance of MedianAlgorithm.
+func New() *MedianAlgorithm {
+	return &MedianAlgorithm{}
+}
+
+// CalculatePrice calculates a median price based on prices from different sources.
+// For an even number of sources the mean of the two middle prices is used.
+func (c *MedianAlgorithm) CalculatePrice(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+	if len(prices) == 0 {
+		return decimal.Zero, fmt.Errorf("not enough data to calculate a median price")
+	}
+
+	sorted := make([]decimal.Decimal, 0, len(prices))
+
+	for _, price := range prices {
+		sorted = append(sorted, price.Price)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	middle := len(sorted) / 2
+
+	if len(sorted)%2 == 0 {
+		return sorted[middle-1].Add(sorted[middle]).Div(decimal.NewFromInt(2)), nil
+	}
+
+	return sorted[middle], nil
+}
@@ -0,0 +1,60 @@
+package medianalgorithm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/medianalgorithm"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+func TestMedianAlgorithm_CalculatePrice(t *testing.T) {
+	t.Run("odd number of sources", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("1.0")},
+			"b": {Price: decimal.MustParse("2.0")},
+			"c": {Price: decimal.MustParse("60.0")},
+		}
+
+		expectedMedianPrice := "2"
+
+		algorithm := medianalgorithm.New()
+
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, expectedMedianPrice, fairPrice.String())
+		}
+	})
+
+	t.Run("even number of sources", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("1.0")},
+			"b": {Price: decimal.MustParse("2.0")},
+			"c": {Price: decimal.MustParse("4.0")},
+			"d": {Price: decimal.MustParse("40.0")},
+		}
+
+		expectedMedianPrice := "3"
+
+		algorithm := medianalgorithm.New()
+
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, expectedMedianPrice, fairPrice.String())
+		}
+	})
+
+	t.Run("empty prices", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{}
+
+		algorithm := medianalgorithm.New()
+
+		_, err := algorithm.CalculatePrice(mockPrices)
+
+		assert.Error(t, err)
+	})
+}
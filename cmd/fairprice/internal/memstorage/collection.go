@@ -50,6 +50,15 @@ func (c *collection[K, V]) Set(key K, val V) {
 	c.data[key] = val
 }
 
+// Update creates or updates an element with the specified key using the update function.
+// The update function receives the current value or the zero value if the key does not exist.
+func (c *collection[K, V]) Update(key K, update func(val V) V) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.data[key] = update(c.data[key])
+}
+
 // Del removes an element with the specified key.
 func (c *collection[K, V]) Del(key K) {
 	c.mutex.Lock()
@@ -58,6 +67,20 @@ func (c *collection[K, V]) Del(key K) {
 	delete(c.data, key)
 }
 
+// Keys takes a snapshot of the keys of the collection.
+func (c *collection[K, V]) Keys() []K {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	keys := make([]K, 0, len(c.data))
+
+	for k := range c.data {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
 // Map takes a snapshot of the collection and returns it as a map.
 func (c *collection[K, V]) Map() map[K]V {
 	c.mutex.RLock()
@@ -1,37 +1,62 @@
 package memstorage
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
 	"tickerprice/cmd/fairprice/internal/types"
 )
 
 // MemoryStorage is a thread-safe storage of prices grouped by ticker, timeslot and source.
 type MemoryStorage struct {
-	tickers *collection[types.Ticker, *collection[types.Timeslot, *collection[types.SourceID, string]]]
+	tickers     *collection[types.Ticker, *collection[types.Timeslot, *collection[types.SourceID, []types.TickerPrice]]]
+	retention   RetentionPolicy
+	timeNowFunc func() time.Time
+	evictions   uint64
 }
 
 // New creates a new initialized instance of MemoryStorage.
-func New() *MemoryStorage {
-	return &MemoryStorage{
-		tickers: createTickerCollection(),
+// The timeslot duration of the retention policy must be positive if any limit is set.
+func New(options ...Option) *MemoryStorage {
+	s := &MemoryStorage{
+		tickers:     createTickerCollection(),
+		timeNowFunc: time.Now,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	limited := s.retention.MaxAge > 0 || s.retention.MaxTimeslots > 0
+
+	if limited && s.retention.TimeslotDuration <= 0 {
+		panic(fmt.Sprintf("memstorage: invalid retention policy %+v", s.retention))
 	}
+
+	return s
 }
 
-// AddPrice adds a new or updates an existing price in the store.
+// AddPrice appends a new price to the series of prices of the source.
 func (s *MemoryStorage) AddPrice(
 	ticker types.Ticker,
 	timeslot types.Timeslot,
 	sourceID types.SourceID,
-	price string,
+	price types.TickerPrice,
 ) {
 	timeslots := s.tickers.GetOrCreate(ticker, createTimeslotCollection)
 
 	sources := timeslots.GetOrCreate(timeslot, createSourceCollection)
 
-	sources.Set(sourceID, price)
+	sources.Update(sourceID, func(prices []types.TickerPrice) []types.TickerPrice {
+		return append(prices, price)
+	})
 }
 
-// GetPrices returns all prices related to ticker and timeslot.
-func (s *MemoryStorage) GetPrices(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID]string {
+// GetPrices returns all prices related to ticker and timeslot in the order they were added.
+func (s *MemoryStorage) GetPrices(ticker types.Ticker, timeslot types.Timeslot) map[types.SourceID][]types.TickerPrice {
 	timeslots, ok := s.tickers.Get(ticker)
 	if !ok {
 		return nil
@@ -42,7 +67,14 @@ func (s *MemoryStorage) GetPrices(ticker types.Ticker, timeslot types.Timeslot)
 		return nil
 	}
 
-	return sources.Map()
+	prices := sources.Map()
+
+	// detach the series from the storage, the source can keep appending to them
+	for sourceID, series := range prices {
+		prices[sourceID] = append([]types.TickerPrice(nil), series...)
+	}
+
+	return prices
 }
 
 // RemovePrices removes all prices related to ticker and timeslot.
@@ -55,14 +87,86 @@ func (s *MemoryStorage) RemovePrices(ticker types.Ticker, timeslot types.Timeslo
 	timeslots.Del(timeslot)
 }
 
-func createTickerCollection() *collection[types.Ticker, *collection[types.Timeslot, *collection[types.SourceID, string]]] {
-	return newCollection[types.Ticker, *collection[types.Timeslot, *collection[types.SourceID, string]]]()
+// RunSweeper periodically evicts timeslots according to the retention policy until the context is done.
+func (s *MemoryStorage) RunSweeper(ctx context.Context, interval time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-time.After(interval):
+			s.Sweep()
+		}
+	}
+}
+
+// Sweep evicts timeslots according to the retention policy and returns the number of evicted timeslots.
+func (s *MemoryStorage) Sweep() int {
+	var evicted int
+
+	for _, ticker := range s.tickers.Keys() {
+		timeslots, ok := s.tickers.Get(ticker)
+		if !ok {
+			continue
+		}
+
+		keys := timeslots.Keys()
+
+		// the oldest timeslots go first
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i] < keys[j]
+		})
+
+		for i, timeslot := range keys {
+			if !s.expired(timeslot, len(keys)-i) {
+				break
+			}
+
+			timeslots.Del(timeslot)
+
+			evicted++
+		}
+	}
+
+	atomic.AddUint64(&s.evictions, uint64(evicted))
+
+	return evicted
+}
+
+// Evictions returns the total number of timeslots evicted by the sweeper.
+func (s *MemoryStorage) Evictions() uint64 {
+	return atomic.LoadUint64(&s.evictions)
+}
+
+// expired reports whether the timeslot must be evicted, remaining is the number of timeslots starting from it.
+func (s *MemoryStorage) expired(timeslot types.Timeslot, remaining int) bool {
+	closedAt := timeslot.ToTime().Add(s.retention.TimeslotDuration + s.retention.GracePeriod)
+
+	age := s.timeNowFunc().Sub(closedAt)
+
+	if age < 0 {
+		return false
+	}
+
+	if s.retention.MaxTimeslots > 0 && remaining > s.retention.MaxTimeslots {
+		return true
+	}
+
+	if s.retention.MaxAge > 0 && age > s.retention.MaxAge {
+		return true
+	}
+
+	return false
+}
+
+func createTickerCollection() *collection[types.Ticker, *collection[types.Timeslot, *collection[types.SourceID, []types.TickerPrice]]] {
+	return newCollection[types.Ticker, *collection[types.Timeslot, *collection[types.SourceID, []types.TickerPrice]]]()
 }
 
-func createTimeslotCollection() *collection[types.Timeslot, *collection[types.SourceID, string]] {
-	return newCollection[types.Timeslot, *collection[types.SourceID, string]]()
+func createTimeslotCollection() *collection[types.Timeslot, *collection[types.SourceID, []types.TickerPrice]] {
+	return newCollection[types.Timeslot, *collection[types.SourceID, []types.TickerPrice]]()
 }
 
-func createSourceCollection() *collection[types.SourceID, string] {
-	return newCollection[types.SourceID, string]()
+func createSourceCollection() *collection[types.SourceID, []types.TickerPrice] {
+	return newCollection[types.SourceID, []types.TickerPrice]()
 }
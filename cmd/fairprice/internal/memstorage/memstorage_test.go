@@ -0,0 +1,84 @@
+package memstorage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/memstorage"
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+func TestMemoryStorage_Sweep(t *testing.T) {
+	var (
+		mockTicker   = types.Ticker("ticker_1")
+		mockSourceID = types.SourceID("source_1")
+		mockPrice    = types.TickerPrice{Ticker: mockTicker, Price: "1.0"}
+
+		mockTimeNowFunc = func() time.Time {
+			return time.Unix(600, 0)
+		}
+	)
+
+	t.Run("max age", func(t *testing.T) {
+		storage := memstorage.New(
+			memstorage.WithRetention(memstorage.RetentionPolicy{MaxAge: 5 * time.Minute, TimeslotDuration: time.Minute}),
+			memstorage.WithTimeNowFunc(mockTimeNowFunc),
+		)
+
+		storage.AddPrice(mockTicker, types.Timeslot(60), mockSourceID, mockPrice)
+		storage.AddPrice(mockTicker, types.Timeslot(300), mockSourceID, mockPrice)
+		storage.AddPrice(mockTicker, types.Timeslot(540), mockSourceID, mockPrice)
+
+		assert.Equal(t, 1, storage.Sweep())
+		assert.Empty(t, storage.GetPrices(mockTicker, types.Timeslot(60)))
+		assert.NotEmpty(t, storage.GetPrices(mockTicker, types.Timeslot(300)))
+		assert.NotEmpty(t, storage.GetPrices(mockTicker, types.Timeslot(540)))
+		assert.Equal(t, uint64(1), storage.Evictions())
+	})
+
+	t.Run("max timeslots", func(t *testing.T) {
+		storage := memstorage.New(
+			memstorage.WithRetention(memstorage.RetentionPolicy{MaxTimeslots: 1, TimeslotDuration: time.Minute}),
+			memstorage.WithTimeNowFunc(mockTimeNowFunc),
+		)
+
+		storage.AddPrice(mockTicker, types.Timeslot(60), mockSourceID, mockPrice)
+		storage.AddPrice(mockTicker, types.Timeslot(300), mockSourceID, mockPrice)
+		storage.AddPrice(mockTicker, types.Timeslot(540), mockSourceID, mockPrice)
+
+		assert.Equal(t, 2, storage.Sweep())
+		assert.NotEmpty(t, storage.GetPrices(mockTicker, types.Timeslot(540)))
+		assert.Equal(t, uint64(2), storage.Evictions())
+	})
+
+	t.Run("open timeslots", func(t *testing.T) {
+		storage := memstorage.New(
+			memstorage.WithRetention(memstorage.RetentionPolicy{
+				MaxAge:           5 * time.Minute,
+				MaxTimeslots:     1,
+				TimeslotDuration: time.Hour,
+				GracePeriod:      time.Minute,
+			}),
+			memstorage.WithTimeNowFunc(func() time.Time {
+				return time.Unix(3630, 0)
+			}),
+		)
+
+		// the first timeslot is older than the max age, but it is waiting for late prices,
+		// the second one has just started
+		storage.AddPrice(mockTicker, types.Timeslot(0), mockSourceID, mockPrice)
+		storage.AddPrice(mockTicker, types.Timeslot(3600), mockSourceID, mockPrice)
+
+		assert.Equal(t, 0, storage.Sweep())
+		assert.NotEmpty(t, storage.GetPrices(mockTicker, types.Timeslot(0)))
+		assert.NotEmpty(t, storage.GetPrices(mockTicker, types.Timeslot(3600)))
+	})
+
+	t.Run("no timeslot duration", func(t *testing.T) {
+		assert.Panics(t, func() {
+			memstorage.New(memstorage.WithRetention(memstorage.RetentionPolicy{MaxAge: time.Minute}))
+		})
+	})
+}
@@ -0,0 +1,30 @@
+package memstorage
+
+import "time"
+
+// Option configures an optional behaviour of MemoryStorage.
+type Option func(*MemoryStorage)
+
+// RetentionPolicy defines which timeslots are evicted by the sweeper.
+// Timeslots are closed once their end and the grace period have passed. Open timeslots are never evicted,
+// they are still receiving prices or waiting to be published.
+type RetentionPolicy struct {
+	MaxAge           time.Duration // maximum time since a timeslot has closed, zero disables the limit
+	MaxTimeslots     int           // maximum number of timeslots per ticker, zero disables the limit
+	TimeslotDuration time.Duration // duration of timeslots, required if any limit is set
+	GracePeriod      time.Duration // time a timeslot waits for late prices after its end before it is published
+}
+
+// WithRetention sets the policy used by the sweeper to evict stale timeslots.
+func WithRetention(policy RetentionPolicy) Option {
+	return func(s *MemoryStorage) {
+		s.retention = policy
+	}
+}
+
+// WithTimeNowFunc sets the function used to determine the age of timeslots.
+func WithTimeNowFunc(timeNowFunc func() time.Time) Option {
+	return func(s *MemoryStorage) {
+		s.timeNowFunc = timeNowFunc
+	}
+}
@@ -26,6 +26,14 @@ func New(price float64, interval time.Duration) *MockPriceSource {
 func (d *MockPriceSource) SubscribePriceStream(
 	ctx context.Context,
 	ticker types.Ticker,
+) (<-chan types.TickerPrice, <-chan error) {
+	return d.SubscribePriceStreams(ctx, []types.Ticker{ticker})
+}
+
+// SubscribePriceStreams subscribes to price updates of several tickers from the source.
+func (d *MockPriceSource) SubscribePriceStreams(
+	ctx context.Context,
+	tickers []types.Ticker,
 ) (<-chan types.TickerPrice, <-chan error) {
 	tickerPrices := make(chan types.TickerPrice)
 	tickerErrors := make(chan error)
@@ -37,10 +45,16 @@ func (d *MockPriceSource) SubscribePriceStream(
 		}()
 
 		for {
-			tickerPrices <- types.TickerPrice{
-				Ticker: ticker,
-				Time:   time.Now(),
-				Price:  fmt.Sprintf("%6f", d.price),
+			for _, ticker := range tickers {
+				select {
+				case tickerPrices <- types.TickerPrice{
+					Ticker: ticker,
+					Time:   time.Now(),
+					Price:  fmt.Sprintf("%6f", d.price),
+				}:
+				case <-ctx.Done():
+					return
+				}
 			}
 
 			select {
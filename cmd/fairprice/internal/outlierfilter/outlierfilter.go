@@ -0,0 +1,106 @@
+package outlierfilter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// minSources is the minimum number of sources required to build a meaningful consensus.
+const minSources = 3
+
+// minRelativeMAD is the lowest median absolute deviation relative to the median, one basis point.
+const minRelativeMAD = 0.0001
+
+// OutlierFilter rejects prices that deviate too far from the median price of all sources.
+type OutlierFilter struct {
+	maxDeviations float64
+	maxPercent    float64
+}
+
+// New creates a new initialized instance of OutlierFilter.
+// maxDeviations is the maximum allowed distance from the median in median absolute deviations,
+// maxPercent is the maximum allowed distance from the median in percent. A zero value disables the check.
+// The MAD is not taken lower than 0.01% of the median, otherwise it is zero when more than a half
+// of sources quote the same price and any other price would be rejected.
+func New(maxDeviations float64, maxPercent float64) *OutlierFilter {
+	return &OutlierFilter{
+		maxDeviations: maxDeviations,
+		maxPercent:    maxPercent,
+	}
+}
+
+// FilterPrices splits prices into accepted and rejected ones.
+func (f *OutlierFilter) FilterPrices(
+	prices map[types.SourceID]types.SourcePrice,
+) (map[types.SourceID]types.SourcePrice, map[types.SourceID]types.Rejection) {
+	if len(prices) < minSources {
+		return prices, nil
+	}
+
+	values := make([]decimal.Decimal, 0, len(prices))
+
+	for _, price := range prices {
+		values = append(values, price.Price)
+	}
+
+	consensus := median(values)
+
+	deviations := make([]decimal.Decimal, 0, len(prices))
+
+	for _, price := range prices {
+		deviations = append(deviations, price.Price.Sub(consensus).Abs())
+	}
+
+	mad := math.Max(median(deviations).Float64(), minRelativeMAD*math.Abs(consensus.Float64()))
+
+	accepted := make(map[types.SourceID]types.SourcePrice, len(prices))
+	rejected := make(map[types.SourceID]types.Rejection)
+
+	for sourceID, price := range prices {
+		deviation := price.Price.Sub(consensus).Abs().Float64()
+
+		// the MAD is zero only if the median is zero
+		if f.maxDeviations > 0 && mad > 0 && deviation > f.maxDeviations*mad {
+			rejected[sourceID] = types.Rejection{
+				Price:     price.Price,
+				Deviation: deviation / mad,
+				Reason:    fmt.Sprintf("deviation from median %v exceeds %v MAD", consensus, f.maxDeviations),
+			}
+
+			continue
+		}
+
+		if f.maxPercent > 0 && !consensus.IsZero() && deviation/math.Abs(consensus.Float64())*100 > f.maxPercent {
+			rejected[sourceID] = types.Rejection{
+				Price:     price.Price,
+				Deviation: deviation / math.Abs(consensus.Float64()) * 100,
+				Reason:    fmt.Sprintf("deviation from median %v exceeds %v%%", consensus, f.maxPercent),
+			}
+
+			continue
+		}
+
+		accepted[sourceID] = price
+	}
+
+	return accepted, rejected
+}
+
+func median(values []decimal.Decimal) decimal.Decimal {
+	sorted := make([]decimal.Decimal, len(values))
+	copy(sorted, values)
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	middle := len(sorted) / 2
+
+	if len(sorted)%2 == 0 {
+		return sorted[middle-1].Add(sorted[middle]).Div(decimal.NewFromInt(2))
+	}
+
+	return sorted[middle]
+}
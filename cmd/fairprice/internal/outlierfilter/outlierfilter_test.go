@@ -0,0 +1,117 @@
+package outlierfilter_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/outlierfilter"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+func TestOutlierFilter_FilterPrices(t *testing.T) {
+	t.Run("reject by median absolute deviation", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("100.0")},
+			"b": {Price: decimal.MustParse("101.0")},
+			"c": {Price: decimal.MustParse("99.0")},
+			"d": {Price: decimal.MustParse("1000.0")},
+		}
+
+		filter := outlierfilter.New(5, 0)
+
+		accepted, rejected := filter.FilterPrices(mockPrices)
+
+		expectedPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("100.0")},
+			"b": {Price: decimal.MustParse("101.0")},
+			"c": {Price: decimal.MustParse("99.0")},
+		}
+
+		assert.Equal(t, expectedPrices, accepted)
+		if assert.Contains(t, rejected, types.SourceID("d")) {
+			assert.Equal(t, "1000", rejected["d"].Price.String())
+		}
+	})
+
+	t.Run("reject by percentage", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("100.0")},
+			"b": {Price: decimal.MustParse("100.0")},
+			"c": {Price: decimal.MustParse("105.0")},
+		}
+
+		filter := outlierfilter.New(0, 1)
+
+		accepted, rejected := filter.FilterPrices(mockPrices)
+
+		expectedPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("100.0")},
+			"b": {Price: decimal.MustParse("100.0")},
+		}
+
+		assert.Equal(t, expectedPrices, accepted)
+		if assert.Contains(t, rejected, types.SourceID("c")) {
+			assert.InDelta(t, 5.0, rejected["c"].Deviation, 1e-9)
+		}
+	})
+
+	t.Run("zero median absolute deviation", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("30000.00")},
+			"b": {Price: decimal.MustParse("30000.00")},
+			"c": {Price: decimal.MustParse("30000.01")},
+		}
+
+		filter := outlierfilter.New(3, 0)
+
+		accepted, rejected := filter.FilterPrices(mockPrices)
+
+		assert.Equal(t, mockPrices, accepted)
+		assert.Empty(t, rejected)
+
+		// the percentage check still applies
+		filter = outlierfilter.New(3, 0.00001)
+
+		accepted, rejected = filter.FilterPrices(mockPrices)
+
+		assert.Len(t, accepted, 2)
+		if assert.Contains(t, rejected, types.SourceID("c")) {
+			assert.False(t, math.IsInf(rejected["c"].Deviation, 0))
+		}
+	})
+
+	t.Run("glitch with zero median absolute deviation", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("30000")},
+			"b": {Price: decimal.MustParse("30000")},
+			"c": {Price: decimal.MustParse("300000")},
+		}
+
+		filter := outlierfilter.New(3, 0)
+
+		accepted, rejected := filter.FilterPrices(mockPrices)
+
+		assert.Len(t, accepted, 2)
+		if assert.Contains(t, rejected, types.SourceID("c")) {
+			// the MAD is one basis point of the median
+			assert.InDelta(t, 90000.0, rejected["c"].Deviation, 1e-6)
+		}
+	})
+
+	t.Run("not enough sources", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("100.0")},
+			"b": {Price: decimal.MustParse("1000.0")},
+		}
+
+		filter := outlierfilter.New(1, 1)
+
+		accepted, rejected := filter.FilterPrices(mockPrices)
+
+		assert.Equal(t, mockPrices, accepted)
+		assert.Empty(t, rejected)
+	})
+}
@@ -0,0 +1,12 @@
+package pricebroker
+
+// Option configures an optional behaviour of PriceBroker.
+type Option func(*PriceBroker)
+
+// WithSlowConsumerPolicy sets what is done with a consumer which does not read prices in time.
+// By default prices are dropped for such a consumer.
+func WithSlowConsumerPolicy(policy SlowConsumerPolicy) Option {
+	return func(b *PriceBroker) {
+		b.slowConsumerPolicy = policy
+	}
+}
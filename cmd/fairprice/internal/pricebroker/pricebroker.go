@@ -0,0 +1,279 @@
+package pricebroker
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"tickerprice/cmd/fairprice/internal/errorstream"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/log"
+)
+
+//go:generate moq -pkg pricebroker_test -out mocks_types_test.go ../types PriceStreamSubscriber
+
+// pricesBufferSize is the number of prices buffered for a consumer that does not read them in time.
+const pricesBufferSize = 64
+
+// ErrSlowConsumer is delivered to a consumer disconnected for not reading prices in time.
+var ErrSlowConsumer = errors.New("slow consumer")
+
+// SlowConsumerPolicy is what is done with a consumer whose buffer of prices is full.
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerDrop drops the price for the consumer.
+	SlowConsumerDrop SlowConsumerPolicy = iota
+	// SlowConsumerDisconnect delivers ErrSlowConsumer and closes the channels of the consumer.
+	SlowConsumerDisconnect
+)
+
+// PriceBroker shares a single upstream subscription per ticker between many consumers.
+// All consumers of a ticker receive identical prices, the upstream subscription is cancelled
+// when the last consumer leaves. A consumer that does not read prices in time never holds back
+// other consumers, its prices are buffered and handled by the slow consumer policy once the buffer is full.
+type PriceBroker struct {
+	upstream           types.PriceStreamSubscriber
+	slowConsumerPolicy SlowConsumerPolicy
+	mutex              sync.Mutex // guards topics, locked before the mutex of a topic
+	topics             map[types.Ticker]*topic
+}
+
+// topic is an upstream subscription to a ticker with its consumers.
+type topic struct {
+	cancel    context.CancelFunc
+	mutex     sync.Mutex
+	consumers map[*consumer]struct{}
+}
+
+// consumer is a single subscription to the broker.
+type consumer struct {
+	mutex  sync.Mutex // guards sends to the channels against closing them
+	prices chan types.TickerPrice
+	errors chan error
+	closed chan struct{} // closed when the channels of the consumer are closed
+}
+
+// New creates a new initialized instance of PriceBroker.
+func New(upstream types.PriceStreamSubscriber, options ...Option) *PriceBroker {
+	b := &PriceBroker{
+		upstream: upstream,
+		topics:   make(map[types.Ticker]*topic),
+	}
+
+	for _, option := range options {
+		option(b)
+	}
+
+	return b
+}
+
+// SubscribePriceStream subscribes to price updates shared with other consumers of the ticker.
+func (b *PriceBroker) SubscribePriceStream(
+	ctx context.Context,
+	ticker types.Ticker,
+) (<-chan types.TickerPrice, <-chan error) {
+	c := &consumer{
+		prices: make(chan types.TickerPrice, pricesBufferSize),
+		errors: make(chan error, errorstream.BufferSize),
+		closed: make(chan struct{}),
+	}
+
+	b.mutex.Lock()
+
+	t, ok := b.topics[ticker]
+	if !ok {
+		t = b.startTopic(ticker)
+		b.topics[ticker] = t
+	}
+
+	t.mutex.Lock()
+	t.consumers[c] = struct{}{}
+	t.mutex.Unlock()
+
+	b.mutex.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.unsubscribe(ticker, t, c)
+
+		case <-c.closed:
+		}
+	}()
+
+	return c.prices, c.errors
+}
+
+// startTopic subscribes to the upstream, must be called with the mutex locked.
+func (b *PriceBroker) startTopic(ticker types.Ticker) *topic {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t := &topic{
+		cancel:    cancel,
+		consumers: make(map[*consumer]struct{}),
+	}
+
+	tickerPrices, tickerErrors := b.upstream.SubscribePriceStream(ctx, ticker)
+
+	go b.runTopic(ticker, t, tickerPrices, tickerErrors)
+
+	return t
+}
+
+func (b *PriceBroker) runTopic(
+	ticker types.Ticker,
+	t *topic,
+	tickerPrices <-chan types.TickerPrice,
+	tickerErrors <-chan error,
+) {
+	for tickerPrices != nil || tickerErrors != nil {
+		select {
+		case tickerPrice, ok := <-tickerPrices:
+			if !ok {
+				tickerPrices = nil
+				continue
+			}
+
+			for _, c := range t.snapshot() {
+				if c.sendPrice(tickerPrice) {
+					continue
+				}
+
+				switch b.slowConsumerPolicy {
+				case SlowConsumerDrop:
+					log.Errorf(context.Background(), "price channel is full, drop price: %v", tickerPrice)
+
+				case SlowConsumerDisconnect:
+					log.Errorf(context.Background(), "price channel is full, disconnect consumer of %s", ticker)
+
+					c.sendError(ErrSlowConsumer)
+					b.unsubscribe(ticker, t, c)
+				}
+			}
+
+		case tickerError, ok := <-tickerErrors:
+			if !ok {
+				tickerErrors = nil
+				continue
+			}
+
+			// errors must not hold back prices of consumers that do not read them
+			for _, c := range t.snapshot() {
+				if !c.sendError(tickerError) {
+					log.Errorf(context.Background(), "error channel is full, drop error: %v", tickerError)
+				}
+			}
+		}
+	}
+
+	// the upstream subscription has finished, nothing will be delivered to the consumers anymore
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.topics[ticker] == t {
+		delete(b.topics, ticker)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for c := range t.consumers {
+		delete(t.consumers, c)
+		c.close()
+	}
+
+	t.cancel()
+}
+
+// snapshot returns the current consumers of the topic, deliveries to them are made without holding any lock.
+func (t *topic) snapshot() []*consumer {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	consumers := make([]*consumer, 0, len(t.consumers))
+
+	for c := range t.consumers {
+		consumers = append(consumers, c)
+	}
+
+	return consumers
+}
+
+func (b *PriceBroker) unsubscribe(ticker types.Ticker, t *topic, c *consumer) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, ok := t.consumers[c]; !ok {
+		return
+	}
+
+	delete(t.consumers, c)
+	c.close()
+
+	// the last consumer has left
+	if len(t.consumers) == 0 {
+		t.cancel()
+
+		if b.topics[ticker] == t {
+			delete(b.topics, ticker)
+		}
+	}
+}
+
+// sendPrice buffers the price for the consumer, it reports false if the buffer is full.
+// Prices are silently skipped for a closed consumer.
+func (c *consumer) sendPrice(tickerPrice types.TickerPrice) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.isClosed() {
+		return true
+	}
+
+	select {
+	case c.prices <- tickerPrice:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendError buffers the error for the consumer, it reports false if the buffer is full.
+func (c *consumer) sendError(err error) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.isClosed() {
+		return true
+	}
+
+	select {
+	case c.errors <- err:
+		return true
+	default:
+		return false
+	}
+}
+
+// isClosed reports whether the channels of the consumer are closed, must be called with the mutex locked.
+func (c *consumer) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *consumer) close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	close(c.prices)
+	close(c.errors)
+	close(c.closed)
+}
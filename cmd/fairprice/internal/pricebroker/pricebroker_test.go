@@ -0,0 +1,219 @@
+package pricebroker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/pricebroker"
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+func TestPriceBroker_SubscribePriceStream(t *testing.T) {
+	var (
+		mockTicker = types.Ticker("ticker_1")
+
+		mockTickerPrice = types.TickerPrice{
+			Ticker: mockTicker,
+			Time:   time.Unix(60, 0),
+			Price:  "1.0",
+		}
+
+		upstreamPrices   = make(chan types.TickerPrice)
+		upstreamFinished = make(chan struct{})
+
+		mockUpstream = &PriceStreamSubscriberMock{
+			SubscribePriceStreamFunc: func(
+				ctx context.Context,
+				ticker types.Ticker,
+			) (
+				<-chan types.TickerPrice,
+				<-chan error,
+			) {
+				errors := make(chan error)
+
+				go func() {
+					<-ctx.Done()
+					close(upstreamPrices)
+					close(errors)
+					close(upstreamFinished)
+				}()
+
+				return upstreamPrices, errors
+			},
+		}
+	)
+
+	broker := pricebroker.New(mockUpstream)
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+
+	tickerPrices1, _ := broker.SubscribePriceStream(ctx1, mockTicker)
+	tickerPrices2, _ := broker.SubscribePriceStream(ctx2, mockTicker)
+
+	upstreamPrices <- mockTickerPrice
+
+	assert.Equal(t, mockTickerPrice, <-tickerPrices1)
+	assert.Equal(t, mockTickerPrice, <-tickerPrices2)
+	assert.Equal(t, 1, len(mockUpstream.SubscribePriceStreamCalls()))
+
+	cancel1()
+
+	_, ok := <-tickerPrices1
+	assert.False(t, ok)
+
+	// the second consumer keeps the upstream subscription alive
+	select {
+	case <-upstreamFinished:
+		assert.Fail(t, "upstream subscription cancelled while a consumer is subscribed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel2()
+
+	_, ok = <-tickerPrices2
+	assert.False(t, ok)
+
+	select {
+	case <-upstreamFinished:
+	case <-time.After(time.Second):
+		assert.Fail(t, "upstream subscription is not cancelled")
+	}
+}
+
+func TestPriceBroker_SubscribePriceStream_SlowConsumer(t *testing.T) {
+	const mockPricesCount = 100
+
+	var (
+		mockTicker1 = types.Ticker("ticker_1")
+		mockTicker2 = types.Ticker("ticker_2")
+
+		upstreamPrices = map[types.Ticker]chan types.TickerPrice{
+			mockTicker1: make(chan types.TickerPrice),
+			mockTicker2: make(chan types.TickerPrice),
+		}
+
+		mockUpstream = &PriceStreamSubscriberMock{
+			SubscribePriceStreamFunc: func(
+				ctx context.Context,
+				ticker types.Ticker,
+			) (
+				<-chan types.TickerPrice,
+				<-chan error,
+			) {
+				tickerPrices := make(chan types.TickerPrice)
+				errors := make(chan error)
+
+				go func() {
+					defer close(tickerPrices)
+					defer close(errors)
+
+					for {
+						select {
+						case tickerPrice := <-upstreamPrices[ticker]:
+							select {
+							case tickerPrices <- tickerPrice:
+							case <-ctx.Done():
+								return
+							}
+
+						case <-ctx.Done():
+							return
+						}
+					}
+				}()
+
+				return tickerPrices, errors
+			},
+		}
+	)
+
+	t.Run("drop", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		broker := pricebroker.New(mockUpstream)
+
+		// the first consumer never reads its prices
+		slowPrices, _ := broker.SubscribePriceStream(ctx, mockTicker1)
+		fastPrices, _ := broker.SubscribePriceStream(ctx, mockTicker1)
+
+		received := make(chan int)
+
+		go func() {
+			var count int
+
+			for range fastPrices {
+				count++
+
+				if count == mockPricesCount {
+					received <- count
+				}
+			}
+		}()
+
+		for i := 0; i < mockPricesCount; i++ {
+			upstreamPrices[mockTicker1] <- types.TickerPrice{Ticker: mockTicker1, Time: time.Unix(int64(i), 0)}
+		}
+
+		select {
+		case count := <-received:
+			assert.Equal(t, mockPricesCount, count)
+		case <-time.After(time.Second):
+			assert.Fail(t, "the fast consumer is held back by the slow consumer")
+		}
+
+		// other tickers are not held back either
+		otherPrices, _ := broker.SubscribePriceStream(ctx, mockTicker2)
+
+		upstreamPrices[mockTicker2] <- types.TickerPrice{Ticker: mockTicker2}
+
+		select {
+		case tickerPrice := <-otherPrices:
+			assert.Equal(t, mockTicker2, tickerPrice.Ticker)
+		case <-time.After(time.Second):
+			assert.Fail(t, "the other ticker is held back by the slow consumer")
+		}
+
+		// the slow consumer still receives the buffered prices
+		tickerPrice, ok := <-slowPrices
+		if assert.True(t, ok) {
+			assert.Equal(t, time.Unix(0, 0), tickerPrice.Time)
+		}
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		broker := pricebroker.New(mockUpstream, pricebroker.WithSlowConsumerPolicy(pricebroker.SlowConsumerDisconnect))
+
+		slowPrices, slowErrors := broker.SubscribePriceStream(ctx, mockTicker1)
+		fastPrices, _ := broker.SubscribePriceStream(ctx, mockTicker1)
+
+		go func() {
+			for range fastPrices {
+			}
+		}()
+
+		for i := 0; i < mockPricesCount; i++ {
+			upstreamPrices[mockTicker1] <- types.TickerPrice{Ticker: mockTicker1, Time: time.Unix(int64(i), 0)}
+		}
+
+		assert.ErrorIs(t, <-slowErrors, pricebroker.ErrSlowConsumer)
+
+		var buffered int
+
+		for range slowPrices {
+			buffered++
+		}
+
+		assert.Less(t, buffered, mockPricesCount)
+	})
+}
@@ -0,0 +1,153 @@
+package quotealgorithm
+
+import (
+	"fmt"
+	"math"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// BestQuoteAlgorithm is an algorithm that consolidates quotes of sources into the best bid and ask.
+type BestQuoteAlgorithm struct{}
+
+// NewBestQuote creates a new initialized instance of BestQuoteAlgorithm.
+func NewBestQuote() *BestQuoteAlgorithm {
+	return &BestQuoteAlgorithm{}
+}
+
+// CalculateQuote calculates the highest bid and the lowest ask based on quotes from different sources.
+// Carried quotes may be no longer available and do not contribute to the quote.
+func (c *BestQuoteAlgorithm) CalculateQuote(prices map[types.SourceID]types.SourcePrice) (types.Quote, error) {
+	var (
+		best  types.Quote
+		found bool
+	)
+
+	for _, price := range prices {
+		if !price.Quoted() || price.Carried {
+			continue
+		}
+
+		if !found || price.Bid.Cmp(best.Bid) > 0 {
+			best.Bid = price.Bid
+		}
+
+		if !found || price.Ask.Cmp(best.Ask) < 0 {
+			best.Ask = price.Ask
+		}
+
+		found = true
+	}
+
+	if !found {
+		return types.Quote{}, fmt.Errorf("not enough data to calculate the best quote")
+	}
+
+	if best.Bid.Cmp(best.Ask) > 0 {
+		return types.Quote{}, fmt.Errorf("crossed quotes: best bid %v is above best ask %v", best.Bid, best.Ask)
+	}
+
+	return best, nil
+}
+
+// SpreadWeightedAlgorithm is an algorithm that weights the price of each source by the inverse of its spread,
+// sources with tighter quotes are closer to the actual market.
+type SpreadWeightedAlgorithm struct{}
+
+// NewSpreadWeighted creates a new initialized instance of SpreadWeightedAlgorithm.
+func NewSpreadWeighted() *SpreadWeightedAlgorithm {
+	return &SpreadWeightedAlgorithm{}
+}
+
+// CalculatePrice calculates a spread-weighted average price based on prices from different sources.
+// Sources without quotes do not contribute to the price.
+func (c *SpreadWeightedAlgorithm) CalculatePrice(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+	weights, err := spreadWeights(prices)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return weightedMean(prices, weights, func(price types.SourcePrice) decimal.Decimal { return price.Price }), nil
+}
+
+// CalculateQuote calculates the spread-weighted average bid and ask based on quotes from different sources.
+func (c *SpreadWeightedAlgorithm) CalculateQuote(prices map[types.SourceID]types.SourcePrice) (types.Quote, error) {
+	weights, err := spreadWeights(prices)
+	if err != nil {
+		return types.Quote{}, err
+	}
+
+	return types.Quote{
+		Bid: weightedMean(prices, weights, func(price types.SourcePrice) decimal.Decimal { return price.Bid }),
+		Ask: weightedMean(prices, weights, func(price types.SourcePrice) decimal.Decimal { return price.Ask }),
+	}, nil
+}
+
+// PriceWeight returns the inverse spread of the source as its weight in the fair price.
+// Sources without quotes have no weight, locked quotes with zero spread have an infinite weight.
+func (c *SpreadWeightedAlgorithm) PriceWeight(_ types.SourceID, price types.SourcePrice) float64 {
+	if !price.Quoted() {
+		return 0
+	}
+
+	spread := price.Quote().Spread()
+
+	if spread.IsZero() {
+		return math.Inf(1)
+	}
+
+	return decimal.NewFromInt(1).Div(spread).Float64()
+}
+
+// spreadWeights returns the inverse spread of every source with a quote.
+// Locked quotes with zero spread outweigh any other quote, so only they are weighted if there are any.
+func spreadWeights(prices map[types.SourceID]types.SourcePrice) (map[types.SourceID]decimal.Decimal, error) {
+	weights := make(map[types.SourceID]decimal.Decimal, len(prices))
+	locked := make(map[types.SourceID]decimal.Decimal)
+
+	one := decimal.NewFromInt(1)
+
+	for sourceID, price := range prices {
+		if !price.Quoted() {
+			continue
+		}
+
+		spread := price.Quote().Spread()
+
+		if spread.IsZero() {
+			locked[sourceID] = one
+			continue
+		}
+
+		weights[sourceID] = one.Div(spread)
+	}
+
+	if len(locked) > 0 {
+		return locked, nil
+	}
+
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("not enough data to calculate a spread-weighted average price")
+	}
+
+	return weights, nil
+}
+
+func weightedMean(
+	prices map[types.SourceID]types.SourcePrice,
+	weights map[types.SourceID]decimal.Decimal,
+	value func(price types.SourcePrice) decimal.Decimal,
+) decimal.Decimal {
+	var (
+		weightedSum decimal.Decimal
+		totalWeight decimal.Decimal
+	)
+
+	for sourceID, weight := range weights {
+		weightedSum = weightedSum.Add(value(prices[sourceID]).Mul(weight))
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	return weightedSum.Div(totalWeight)
+}
@@ -0,0 +1,119 @@
+package quotealgorithm_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/quotealgorithm"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+var mockPrices = map[types.SourceID]types.SourcePrice{
+	"a": {Price: decimal.MustParse("100"), Bid: decimal.MustParse("99"), Ask: decimal.MustParse("101")},
+	"b": {Price: decimal.MustParse("100.25"), Bid: decimal.MustParse("100"), Ask: decimal.MustParse("100.5")},
+	"c": {Price: decimal.MustParse("500")},
+}
+
+func TestBestQuoteAlgorithm_CalculateQuote(t *testing.T) {
+	algorithm := quotealgorithm.NewBestQuote()
+
+	t.Run("success", func(t *testing.T) {
+		quote, err := algorithm.CalculateQuote(mockPrices)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "100", quote.Bid.String())
+			assert.Equal(t, "100.5", quote.Ask.String())
+		}
+	})
+
+	t.Run("carried quote", func(t *testing.T) {
+		prices := map[types.SourceID]types.SourcePrice{
+			"a": mockPrices["a"],
+			"d": {Bid: decimal.MustParse("100.8"), Ask: decimal.MustParse("100.9"), Carried: true},
+		}
+
+		quote, err := algorithm.CalculateQuote(prices)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "99", quote.Bid.String())
+			assert.Equal(t, "101", quote.Ask.String())
+		}
+	})
+
+	t.Run("crossed quotes", func(t *testing.T) {
+		prices := map[types.SourceID]types.SourcePrice{
+			"a": mockPrices["a"],
+			"e": {Bid: decimal.MustParse("102"), Ask: decimal.MustParse("103")},
+		}
+
+		_, err := algorithm.CalculateQuote(prices)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("no quotes", func(t *testing.T) {
+		_, err := algorithm.CalculateQuote(map[types.SourceID]types.SourcePrice{"c": mockPrices["c"]})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestSpreadWeightedAlgorithm_CalculatePrice(t *testing.T) {
+	algorithm := quotealgorithm.NewSpreadWeighted()
+
+	t.Run("success", func(t *testing.T) {
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		// weights are 1 / 2 and 1 / 0.5: (100 * 0.5 + 100.25 * 2) / 2.5
+		if assert.NoError(t, err) {
+			assert.Equal(t, "100.2", fairPrice.String())
+		}
+	})
+
+	t.Run("locked quote", func(t *testing.T) {
+		prices := map[types.SourceID]types.SourcePrice{
+			"a": mockPrices["a"],
+			"e": {Price: decimal.MustParse("100.4"), Bid: decimal.MustParse("100.4"), Ask: decimal.MustParse("100.4")},
+		}
+
+		fairPrice, err := algorithm.CalculatePrice(prices)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "100.4", fairPrice.String())
+		}
+	})
+
+	t.Run("no quotes", func(t *testing.T) {
+		_, err := algorithm.CalculatePrice(map[types.SourceID]types.SourcePrice{"c": mockPrices["c"]})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestSpreadWeightedAlgorithm_CalculateQuote(t *testing.T) {
+	algorithm := quotealgorithm.NewSpreadWeighted()
+
+	quote, err := algorithm.CalculateQuote(mockPrices)
+
+	// (99 * 0.5 + 100 * 2) / 2.5, (101 * 0.5 + 100.5 * 2) / 2.5
+	if assert.NoError(t, err) {
+		assert.Equal(t, "99.8", quote.Bid.String())
+		assert.Equal(t, "100.6", quote.Ask.String())
+		assert.Equal(t, "100.2", quote.Mid().String())
+		assert.Equal(t, "0.8", quote.Spread().String())
+	}
+}
+
+func TestSpreadWeightedAlgorithm_PriceWeight(t *testing.T) {
+	algorithm := quotealgorithm.NewSpreadWeighted()
+
+	locked := types.SourcePrice{Price: decimal.MustParse("100.4"), Bid: decimal.MustParse("100.4"), Ask: decimal.MustParse("100.4")}
+
+	assert.Equal(t, 0.5, algorithm.PriceWeight("a", mockPrices["a"]))
+	assert.Equal(t, 2.0, algorithm.PriceWeight("b", mockPrices["b"]))
+	assert.Equal(t, 0.0, algorithm.PriceWeight("c", mockPrices["c"]))
+	assert.True(t, math.IsInf(algorithm.PriceWeight("e", locked), 1))
+}
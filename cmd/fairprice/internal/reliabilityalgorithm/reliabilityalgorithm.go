@@ -0,0 +1,185 @@
+package reliabilityalgorithm
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// Config defines how reliability scores are learned.
+type Config struct {
+	Smoothing      float64 // weight of the latest timeslot in the moving average of errors, from 0 to 1
+	Tolerance      float64 // relative error at which the score of a source is halved. example: 0.001 for 0.1%
+	MissingPenalty float64 // relative error counted for a known source without a fresh price in a timeslot
+}
+
+// DefaultConfig is the config which forgets most of the history after about 20 timeslots.
+var DefaultConfig = Config{
+	Smoothing:      0.1,
+	Tolerance:      0.001,
+	MissingPenalty: 0.01,
+}
+
+// Reliability is the learned reliability of a source.
+type Reliability struct {
+	Score     float64 // weight of the source in the fair price, from 0 to 1
+	MeanError float64 // moving average of relative errors from the fair price
+	Timeslots int     // number of timeslots the source has contributed a price to
+	Missed    int     // number of timeslots the source has not provided a fresh price in, including carried prices
+}
+
+// ReliabilityAlgorithm is an algorithm that weights the price of each source by its reliability score.
+// The score of a source goes down when its prices deviate from fair prices or it misses timeslots
+// and recovers when it is close to fair prices again. New sources start with the maximum score.
+// Scores of an instance are learned from prices of a single ticker. FairPriceSource calculates prices
+// of every ticker by the instance returned by ForTicker, so sources missing a ticker are penalized for it only.
+type ReliabilityAlgorithm struct {
+	config  Config
+	mutex   sync.RWMutex
+	sources map[types.SourceID]*Reliability
+	tickers map[types.Ticker]*ReliabilityAlgorithm
+}
+
+// New creates a new initialized instance of ReliabilityAlgorithm.
+func New(config Config) *ReliabilityAlgorithm {
+	if config.Smoothing <= 0 || config.Smoothing > 1 || config.Tolerance <= 0 || config.MissingPenalty < 0 {
+		panic(fmt.Sprintf("reliabilityalgorithm: invalid config %+v", config))
+	}
+
+	return &ReliabilityAlgorithm{
+		config:  config,
+		sources: make(map[types.SourceID]*Reliability),
+		tickers: make(map[types.Ticker]*ReliabilityAlgorithm),
+	}
+}
+
+// ForTicker returns the instance learning scores of sources from prices of the ticker.
+func (a *ReliabilityAlgorithm) ForTicker(ticker types.Ticker) types.PriceAlgorithm {
+	return a.ticker(ticker)
+}
+
+// TickerScores returns the reliability of every source seen in prices of the ticker.
+func (a *ReliabilityAlgorithm) TickerScores(ticker types.Ticker) map[types.SourceID]Reliability {
+	return a.ticker(ticker).Scores()
+}
+
+// CalculatePrice calculates an average price based on prices from different sources weighted by their scores.
+func (a *ReliabilityAlgorithm) CalculatePrice(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+	var (
+		weightedSum decimal.Decimal
+		totalWeight decimal.Decimal
+	)
+
+	for sourceID, price := range prices {
+		weight := decimal.NewFromFloat(a.score(sourceID))
+
+		weightedSum = weightedSum.Add(price.Price.Mul(weight))
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	if totalWeight.IsZero() {
+		return decimal.Zero, fmt.Errorf("not enough data to calculate a reliability-weighted price")
+	}
+
+	return weightedSum.Div(totalWeight), nil
+}
+
+// PriceWeight returns the reliability score of the source as its weight in the fair price.
+func (a *ReliabilityAlgorithm) PriceWeight(sourceID types.SourceID, _ types.SourcePrice) float64 {
+	return a.score(sourceID)
+}
+
+// ObserveFairPrice updates scores of sources from the distance of their prices to the fair price.
+// Known sources without a price or with a carried price are penalized.
+func (a *ReliabilityAlgorithm) ObserveFairPrice(
+	prices map[types.SourceID]types.SourcePrice,
+	fairPrice decimal.Decimal,
+) {
+	if fairPrice.IsZero() {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for sourceID, price := range prices {
+		if price.Carried {
+			continue
+		}
+
+		relativeError := math.Abs(price.Price.Sub(fairPrice).Div(fairPrice).Float64())
+
+		reliability := a.source(sourceID)
+		reliability.Timeslots++
+
+		a.update(reliability, relativeError)
+	}
+
+	for sourceID, reliability := range a.sources {
+		if price, ok := prices[sourceID]; ok && !price.Carried {
+			continue
+		}
+
+		reliability.Missed++
+
+		a.update(reliability, a.config.MissingPenalty)
+	}
+}
+
+// Scores returns the reliability of every source seen so far.
+func (a *ReliabilityAlgorithm) Scores() map[types.SourceID]Reliability {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	scores := make(map[types.SourceID]Reliability, len(a.sources))
+
+	for sourceID, reliability := range a.sources {
+		scores[sourceID] = *reliability
+	}
+
+	return scores
+}
+
+func (a *ReliabilityAlgorithm) score(sourceID types.SourceID) float64 {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if reliability, ok := a.sources[sourceID]; ok {
+		return reliability.Score
+	}
+
+	return 1
+}
+
+func (a *ReliabilityAlgorithm) ticker(ticker types.Ticker) *ReliabilityAlgorithm {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	algorithm, ok := a.tickers[ticker]
+	if !ok {
+		algorithm = New(a.config)
+		a.tickers[ticker] = algorithm
+	}
+
+	return algorithm
+}
+
+// source returns the reliability of the source, must be called with the mutex locked.
+func (a *ReliabilityAlgorithm) source(sourceID types.SourceID) *Reliability {
+	reliability, ok := a.sources[sourceID]
+	if !ok {
+		reliability = &Reliability{Score: 1}
+		a.sources[sourceID] = reliability
+	}
+
+	return reliability
+}
+
+// update adds the error to the moving average and recalculates the score, must be called with the mutex locked.
+func (a *ReliabilityAlgorithm) update(reliability *Reliability, relativeError float64) {
+	reliability.MeanError += a.config.Smoothing * (relativeError - reliability.MeanError)
+	reliability.Score = 1 / (1 + reliability.MeanError/a.config.Tolerance)
+}
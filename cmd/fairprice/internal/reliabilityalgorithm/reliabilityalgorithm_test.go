@@ -0,0 +1,115 @@
+package reliabilityalgorithm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/fairpricesource"
+	"tickerprice/cmd/fairprice/internal/reliabilityalgorithm"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+func TestReliabilityAlgorithm_CalculatePrice(t *testing.T) {
+	t.Run("new sources are weighted equally", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("100")},
+			"b": {Price: decimal.MustParse("110")},
+		}
+
+		algorithm := reliabilityalgorithm.New(reliabilityalgorithm.DefaultConfig)
+
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "105", fairPrice.String())
+		}
+	})
+
+	t.Run("empty prices", func(t *testing.T) {
+		algorithm := reliabilityalgorithm.New(reliabilityalgorithm.DefaultConfig)
+
+		_, err := algorithm.CalculatePrice(map[types.SourceID]types.SourcePrice{})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestReliabilityAlgorithm_ObserveFairPrice(t *testing.T) {
+	algorithm := reliabilityalgorithm.New(reliabilityalgorithm.Config{
+		Smoothing:      0.5,
+		Tolerance:      0.01,
+		MissingPenalty: 0.01,
+	})
+
+	mockPrices := map[types.SourceID]types.SourcePrice{
+		"good":  {Price: decimal.MustParse("100")},
+		"bad":   {Price: decimal.MustParse("110")},
+		"stale": {Price: decimal.MustParse("100")},
+	}
+
+	algorithm.ObserveFairPrice(mockPrices, decimal.MustParse("100"))
+
+	// the price of the stale source is carried forward and counted as missed
+	mockPrices["stale"] = types.SourcePrice{Price: decimal.MustParse("100"), Carried: true}
+
+	for i := 0; i < 10; i++ {
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		algorithm.ObserveFairPrice(mockPrices, fairPrice)
+	}
+
+	scores := algorithm.Scores()
+
+	// the chronically off source loses influence, so the fair price moves towards the good source
+	assert.Greater(t, scores["good"].Score, scores["bad"].Score)
+	assert.Less(t, scores["stale"].Score, 1.0)
+	assert.Equal(t, 10, scores["stale"].Missed)
+	assert.Equal(t, 11, scores["good"].Timeslots)
+
+	fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+	if assert.NoError(t, err) {
+		assert.Less(t, fairPrice.Float64(), 105.0)
+	}
+
+	assert.Equal(t, scores["bad"].Score, algorithm.PriceWeight("bad", mockPrices["bad"]))
+}
+
+func TestReliabilityAlgorithm_ForTicker(t *testing.T) {
+	algorithm := reliabilityalgorithm.New(reliabilityalgorithm.DefaultConfig)
+
+	btcAlgorithm, ok := algorithm.ForTicker("BTC_USD").(fairpricesource.FairPriceObserver)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	ethAlgorithm, ok := algorithm.ForTicker("ETH_USD").(fairpricesource.FairPriceObserver)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	btcAlgorithm.ObserveFairPrice(map[types.SourceID]types.SourcePrice{
+		"a": {Price: decimal.MustParse("30000")},
+		"b": {Price: decimal.MustParse("30000")},
+	}, decimal.MustParse("30000"))
+
+	// the first source does not quote the second ticker
+	for i := 0; i < 10; i++ {
+		ethAlgorithm.ObserveFairPrice(map[types.SourceID]types.SourcePrice{
+			"b": {Price: decimal.MustParse("2000")},
+		}, decimal.MustParse("2000"))
+	}
+
+	btcScores := algorithm.TickerScores("BTC_USD")
+
+	assert.Equal(t, 0, btcScores["a"].Missed)
+	assert.Equal(t, 1.0, btcScores["a"].Score)
+	assert.NotContains(t, algorithm.TickerScores("ETH_USD"), types.SourceID("a"))
+	assert.Equal(t, 11, btcScores["b"].Timeslots+algorithm.TickerScores("ETH_USD")["b"].Timeslots)
+	assert.Same(t, algorithm.ForTicker("BTC_USD"), algorithm.ForTicker("BTC_USD"))
+}
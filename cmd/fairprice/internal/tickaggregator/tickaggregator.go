@@ -0,0 +1,197 @@
+package tickaggregator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// LastAggregator uses the last price reported by the source in the timeslot.
+type LastAggregator struct{}
+
+// NewLast creates a new initialized instance of LastAggregator.
+func NewLast() *LastAggregator {
+	return &LastAggregator{}
+}
+
+// AggregateTicks returns the last tick of the series with the total volume of the timeslot
+// and the last quote reported in the timeslot.
+func (a *LastAggregator) AggregateTicks(_, _ time.Time, ticks []types.SourcePrice) (types.SourcePrice, error) {
+	if len(ticks) == 0 {
+		return types.SourcePrice{}, fmt.Errorf("no ticks to aggregate")
+	}
+
+	ticks = sortTicks(ticks)
+
+	last := ticks[len(ticks)-1]
+
+	quote := lastQuote(ticks)
+
+	return types.SourcePrice{
+		Time:   last.Time,
+		Price:  last.Price,
+		Volume: totalVolume(ticks),
+		Bid:    quote.Bid,
+		Ask:    quote.Ask,
+	}, nil
+}
+
+// MeanAggregator uses the mean of all prices reported by the source in the timeslot.
+type MeanAggregator struct{}
+
+// NewMean creates a new initialized instance of MeanAggregator.
+func NewMean() *MeanAggregator {
+	return &MeanAggregator{}
+}
+
+// AggregateTicks returns the mean price of the series with the total volume of the timeslot.
+// The bid and the ask are the means of quotes reported in the timeslot.
+func (a *MeanAggregator) AggregateTicks(_, _ time.Time, ticks []types.SourcePrice) (types.SourcePrice, error) {
+	if len(ticks) == 0 {
+		return types.SourcePrice{}, fmt.Errorf("no ticks to aggregate")
+	}
+
+	ticks = sortTicks(ticks)
+
+	var (
+		sum    decimal.Decimal
+		bidSum decimal.Decimal
+		askSum decimal.Decimal
+		quotes int64
+	)
+
+	for _, tick := range ticks {
+		sum = sum.Add(tick.Price)
+
+		if tick.Quoted() {
+			bidSum = bidSum.Add(tick.Bid)
+			askSum = askSum.Add(tick.Ask)
+			quotes++
+		}
+	}
+
+	price := types.SourcePrice{
+		Time:   ticks[len(ticks)-1].Time,
+		Price:  sum.Div(decimal.NewFromInt(int64(len(ticks)))),
+		Volume: totalVolume(ticks),
+	}
+
+	if quotes > 0 {
+		price.Bid = bidSum.Div(decimal.NewFromInt(quotes))
+		price.Ask = askSum.Div(decimal.NewFromInt(quotes))
+	}
+
+	return price, nil
+}
+
+// TimeWeightedAggregator weights each price by the time it was in effect during the timeslot.
+type TimeWeightedAggregator struct{}
+
+// NewTimeWeighted creates a new initialized instance of TimeWeightedAggregator.
+func NewTimeWeighted() *TimeWeightedAggregator {
+	return &TimeWeightedAggregator{}
+}
+
+// AggregateTicks returns the time-weighted average price of the series with the total volume of the timeslot.
+// Each price is in effect from its own time until the next tick or the end of the timeslot,
+// the bid and the ask are weighted the same way over ticks with quotes.
+func (a *TimeWeightedAggregator) AggregateTicks(
+	_, end time.Time,
+	ticks []types.SourcePrice,
+) (types.SourcePrice, error) {
+	if len(ticks) == 0 {
+		return types.SourcePrice{}, fmt.Errorf("no ticks to aggregate")
+	}
+
+	ticks = sortTicks(ticks)
+
+	var (
+		weightedSum    decimal.Decimal
+		totalDuration  time.Duration
+		weightedBid    decimal.Decimal
+		weightedAsk    decimal.Decimal
+		quotedDuration time.Duration
+	)
+
+	for i, tick := range ticks {
+		until := end
+		if i+1 < len(ticks) {
+			until = ticks[i+1].Time
+		}
+
+		duration := until.Sub(tick.Time)
+		if duration <= 0 {
+			continue
+		}
+
+		weight := decimal.NewFromInt(int64(duration))
+
+		weightedSum = weightedSum.Add(tick.Price.Mul(weight))
+		totalDuration += duration
+
+		if tick.Quoted() {
+			weightedBid = weightedBid.Add(tick.Bid.Mul(weight))
+			weightedAsk = weightedAsk.Add(tick.Ask.Mul(weight))
+			quotedDuration += duration
+		}
+	}
+
+	last := ticks[len(ticks)-1]
+
+	price := last.Price
+
+	// all ticks arrived at the very end of the timeslot
+	if totalDuration > 0 {
+		price = weightedSum.Div(decimal.NewFromInt(int64(totalDuration)))
+	}
+
+	quote := lastQuote(ticks)
+
+	if quotedDuration > 0 {
+		quote.Bid = weightedBid.Div(decimal.NewFromInt(int64(quotedDuration)))
+		quote.Ask = weightedAsk.Div(decimal.NewFromInt(int64(quotedDuration)))
+	}
+
+	return types.SourcePrice{
+		Time:   last.Time,
+		Price:  price,
+		Volume: totalVolume(ticks),
+		Bid:    quote.Bid,
+		Ask:    quote.Ask,
+	}, nil
+}
+
+func sortTicks(ticks []types.SourcePrice) []types.SourcePrice {
+	sorted := make([]types.SourcePrice, len(ticks))
+	copy(sorted, ticks)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	return sorted
+}
+
+// lastQuote returns the quote of the latest tick with a quote, zero if no tick has a quote.
+func lastQuote(ticks []types.SourcePrice) types.Quote {
+	for i := len(ticks) - 1; i >= 0; i-- {
+		if ticks[i].Quoted() {
+			return ticks[i].Quote()
+		}
+	}
+
+	return types.Quote{}
+}
+
+func totalVolume(ticks []types.SourcePrice) decimal.Decimal {
+	var volume decimal.Decimal
+
+	for _, tick := range ticks {
+		volume = volume.Add(tick.Volume)
+	}
+
+	return volume
+}
@@ -0,0 +1,118 @@
+package tickaggregator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/tickaggregator"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+var (
+	mockStart = time.Unix(60, 0)
+	mockEnd   = time.Unix(120, 0)
+
+	mockTicks = []types.SourcePrice{
+		{Time: time.Unix(60, 0), Price: decimal.MustParse("1.0"), Volume: decimal.MustParse("1.0")},
+		{Time: time.Unix(90, 0), Price: decimal.MustParse("4.0"), Volume: decimal.MustParse("2.0")},
+		{Time: time.Unix(110, 0), Price: decimal.MustParse("7.0")},
+	}
+
+	mockQuotedTicks = []types.SourcePrice{
+		{Time: time.Unix(60, 0), Price: decimal.MustParse("1.0"), Bid: decimal.MustParse("0.9"), Ask: decimal.MustParse("1.1")},
+		{Time: time.Unix(90, 0), Price: decimal.MustParse("4.0"), Bid: decimal.MustParse("3.9"), Ask: decimal.MustParse("4.3")},
+		{Time: time.Unix(110, 0), Price: decimal.MustParse("7.0")},
+	}
+)
+
+func TestLastAggregator_AggregateTicks(t *testing.T) {
+	aggregator := tickaggregator.NewLast()
+
+	price, err := aggregator.AggregateTicks(mockStart, mockEnd, mockTicks)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Unix(110, 0), price.Time)
+		assert.Equal(t, "7", price.Price.String())
+		assert.Equal(t, "3", price.Volume.String())
+		assert.False(t, price.Quoted())
+	}
+
+	price, err = aggregator.AggregateTicks(mockStart, mockEnd, mockQuotedTicks)
+
+	// the last tick has no quote, the quote of the previous tick is still in effect
+	if assert.NoError(t, err) {
+		assert.Equal(t, "7", price.Price.String())
+		assert.Equal(t, "3.9", price.Bid.String())
+		assert.Equal(t, "4.3", price.Ask.String())
+	}
+
+	_, err = aggregator.AggregateTicks(mockStart, mockEnd, nil)
+
+	assert.Error(t, err)
+}
+
+func TestMeanAggregator_AggregateTicks(t *testing.T) {
+	aggregator := tickaggregator.NewMean()
+
+	price, err := aggregator.AggregateTicks(mockStart, mockEnd, mockTicks)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Unix(110, 0), price.Time)
+		assert.Equal(t, "4", price.Price.String())
+		assert.Equal(t, "3", price.Volume.String())
+	}
+
+	price, err = aggregator.AggregateTicks(mockStart, mockEnd, mockQuotedTicks)
+
+	// means of the two quoted ticks
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2.4", price.Bid.String())
+		assert.Equal(t, "2.7", price.Ask.String())
+	}
+}
+
+func TestTimeWeightedAggregator_AggregateTicks(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		aggregator := tickaggregator.NewTimeWeighted()
+
+		price, err := aggregator.AggregateTicks(mockStart, mockEnd, mockTicks)
+
+		// 1.0 for 30s, 4.0 for 20s, 7.0 for 10s: (30 + 80 + 70) / 60
+		expectedPrice := "3"
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, expectedPrice, price.Price.String())
+			assert.Equal(t, "3", price.Volume.String())
+		}
+	})
+
+	t.Run("quotes", func(t *testing.T) {
+		aggregator := tickaggregator.NewTimeWeighted()
+
+		price, err := aggregator.AggregateTicks(mockStart, mockEnd, mockQuotedTicks)
+
+		// quotes are weighted over 30s and 20s: (0.9*30 + 3.9*20) / 50, (1.1*30 + 4.3*20) / 50
+		if assert.NoError(t, err) {
+			assert.Equal(t, "3", price.Price.String())
+			assert.Equal(t, "2.1", price.Bid.String())
+			assert.Equal(t, "2.38", price.Ask.String())
+		}
+	})
+
+	t.Run("tick at the end of the timeslot", func(t *testing.T) {
+		aggregator := tickaggregator.NewTimeWeighted()
+
+		mockTicks := []types.SourcePrice{
+			{Time: mockEnd, Price: decimal.MustParse("5.0")},
+		}
+
+		price, err := aggregator.AggregateTicks(mockStart, mockEnd, mockTicks)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "5", price.Price.String())
+		}
+	})
+}
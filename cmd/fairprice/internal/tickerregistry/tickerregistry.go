@@ -0,0 +1,164 @@
+package tickerregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// DefaultPrecision is the number of fractional digits of prices of unregistered tickers.
+const DefaultPrecision = 10
+
+// TickerInfo is the metadata of a traded pair.
+type TickerInfo struct {
+	Ticker    types.Ticker    `json:"ticker"`
+	Base      string          `json:"base"`      // base asset. example: "BTC"
+	Quote     string          `json:"quote"`     // quote asset. example: "USD"
+	Precision int32           `json:"precision"` // number of fractional digits of prices
+	TickSize  decimal.Decimal `json:"tick_size"` // minimum price increment, zero if prices are limited by precision only
+	MinPrice  decimal.Decimal `json:"min_price"` // lowest sane price, zero disables the bound
+	MaxPrice  decimal.Decimal `json:"max_price"` // highest sane price, zero disables the bound
+}
+
+// Validate checks the metadata is consistent.
+func (i TickerInfo) Validate() error {
+	if i.Ticker == "" {
+		return fmt.Errorf("empty ticker")
+	}
+
+	if i.Precision < 0 {
+		return fmt.Errorf("ticker %s: negative precision %d", i.Ticker, i.Precision)
+	}
+
+	if i.TickSize.Sign() < 0 {
+		return fmt.Errorf("ticker %s: negative tick size %v", i.Ticker, i.TickSize)
+	}
+
+	// the tick size must be representable with the precision
+	if !i.TickSize.Round(i.Precision, decimal.RoundDown).Equal(i.TickSize) {
+		return fmt.Errorf("ticker %s: tick size %v exceeds precision %d", i.Ticker, i.TickSize, i.Precision)
+	}
+
+	if i.MinPrice.Sign() < 0 || i.MaxPrice.Sign() < 0 {
+		return fmt.Errorf("ticker %s: negative price bound", i.Ticker)
+	}
+
+	if !i.MaxPrice.IsZero() && i.MinPrice.Cmp(i.MaxPrice) > 0 {
+		return fmt.Errorf("ticker %s: min price %v exceeds max price %v", i.Ticker, i.MinPrice, i.MaxPrice)
+	}
+
+	return nil
+}
+
+// CheckPrice returns an error if the price is outside the sane bounds of the ticker.
+func (i TickerInfo) CheckPrice(price decimal.Decimal) error {
+	if price.Sign() <= 0 {
+		return fmt.Errorf("price %v is not positive", price)
+	}
+
+	if !i.MinPrice.IsZero() && price.Cmp(i.MinPrice) < 0 {
+		return fmt.Errorf("price %v is below %v", price, i.MinPrice)
+	}
+
+	if !i.MaxPrice.IsZero() && price.Cmp(i.MaxPrice) > 0 {
+		return fmt.Errorf("price %v is above %v", price, i.MaxPrice)
+	}
+
+	return nil
+}
+
+// FormatPrice rounds the price to the tick size and precision of the ticker
+// and prints it with exactly the precision number of fractional digits.
+func (i TickerInfo) FormatPrice(price decimal.Decimal, mode decimal.RoundingMode) string {
+	rounded := price.RoundStep(i.TickSize, mode).Round(i.Precision, mode)
+
+	return rounded.StringFixed(i.Precision)
+}
+
+// Registry is a thread-safe set of ticker metadata.
+type Registry struct {
+	mutex   sync.RWMutex
+	tickers map[types.Ticker]TickerInfo
+}
+
+// New creates a new initialized instance of Registry.
+func New() *Registry {
+	return &Registry{
+		tickers: make(map[types.Ticker]TickerInfo),
+	}
+}
+
+// Load creates a registry from a JSON array of ticker metadata.
+func Load(r io.Reader) (*Registry, error) {
+	var infos []TickerInfo
+
+	if err := json.NewDecoder(r).Decode(&infos); err != nil {
+		return nil, fmt.Errorf("decode tickers: %w", err)
+	}
+
+	registry := New()
+
+	for _, info := range infos {
+		if err := registry.Register(info); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// Register adds the metadata of a ticker or replaces the existing one.
+func (r *Registry) Register(info TickerInfo) error {
+	if err := info.Validate(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.tickers[info.Ticker] = info
+
+	return nil
+}
+
+// Lookup returns the metadata of the ticker.
+func (r *Registry) Lookup(ticker types.Ticker) (TickerInfo, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	info, ok := r.tickers[ticker]
+
+	return info, ok
+}
+
+// FormatPrice rounds the price to the tick size and precision of the ticker.
+// Prices of unregistered tickers are rounded to DefaultPrecision and printed without trailing zeros.
+func (r *Registry) FormatPrice(ticker types.Ticker, price decimal.Decimal, mode decimal.RoundingMode) string {
+	info, ok := r.Lookup(ticker)
+	if !ok {
+		return price.Round(DefaultPrecision, mode).String()
+	}
+
+	return info.FormatPrice(price, mode)
+}
+
+// Tickers returns all registered tickers in alphabetical order.
+func (r *Registry) Tickers() []types.Ticker {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tickers := make([]types.Ticker, 0, len(r.tickers))
+
+	for ticker := range r.tickers {
+		tickers = append(tickers, ticker)
+	}
+
+	sort.Slice(tickers, func(i, j int) bool { return tickers[i] < tickers[j] })
+
+	return tickers
+}
@@ -0,0 +1,83 @@
+package tickerregistry_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/tickerregistry"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		registry, err := tickerregistry.Load(strings.NewReader(`[
+			{"ticker": "BTC_USD", "base": "BTC", "quote": "USD", "precision": 2, "tick_size": "0.5", "max_price": "1000000"},
+			{"ticker": "ETH_BTC", "base": "ETH", "quote": "BTC", "precision": 6}
+		]`))
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, []types.Ticker{"BTC_USD", "ETH_BTC"}, registry.Tickers())
+
+		info, ok := registry.Lookup("BTC_USD")
+
+		if assert.True(t, ok) {
+			assert.Equal(t, "BTC", info.Base)
+			assert.Equal(t, "USD", info.Quote)
+			assert.Equal(t, "0.5", info.TickSize.String())
+		}
+
+		_, ok = registry.Lookup("XRP_USD")
+
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid ticker", func(t *testing.T) {
+		_, err := tickerregistry.Load(strings.NewReader(`[{"ticker": "BTC_USD", "precision": 1, "tick_size": "0.01"}]`))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTickerInfo_FormatPrice(t *testing.T) {
+	info := tickerregistry.TickerInfo{
+		Ticker:    "BTC_USD",
+		Precision: 2,
+		TickSize:  decimal.MustParse("0.05"),
+	}
+
+	assert.Equal(t, "1.25", info.FormatPrice(decimal.MustParse("1.26"), decimal.RoundHalfUp))
+	assert.Equal(t, "1.30", info.FormatPrice(decimal.MustParse("1.26"), decimal.RoundCeiling))
+	assert.Equal(t, "7.00", info.FormatPrice(decimal.NewFromInt(7), decimal.RoundHalfUp))
+}
+
+func TestRegistry_FormatPrice(t *testing.T) {
+	registry := tickerregistry.New()
+
+	err := registry.Register(tickerregistry.TickerInfo{Ticker: "BTC_USD", Precision: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.30", registry.FormatPrice("BTC_USD", decimal.MustParse("1.3"), decimal.RoundHalfUp))
+
+	// unregistered tickers are rounded to the default precision without trailing zeros
+	assert.Equal(t, "0.3333333333", registry.FormatPrice("ETH_USD", decimal.NewFromInt(1).Div(decimal.NewFromInt(3)), decimal.RoundHalfUp))
+	assert.Equal(t, "1.3", registry.FormatPrice("ETH_USD", decimal.MustParse("1.30"), decimal.RoundHalfUp))
+}
+
+func TestTickerInfo_CheckPrice(t *testing.T) {
+	info := tickerregistry.TickerInfo{
+		Ticker:   "BTC_USD",
+		MinPrice: decimal.NewFromInt(1000),
+		MaxPrice: decimal.NewFromInt(1000000),
+	}
+
+	assert.NoError(t, info.CheckPrice(decimal.NewFromInt(60000)))
+	assert.Error(t, info.CheckPrice(decimal.NewFromInt(6)))
+	assert.Error(t, info.CheckPrice(decimal.NewFromInt(6000000)))
+	assert.Error(t, info.CheckPrice(decimal.Zero))
+}
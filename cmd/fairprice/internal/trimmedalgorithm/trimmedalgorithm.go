@@ -0,0 +1,104 @@
+package trimmedalgorithm
+
+import (
+	"fmt"
+	"sort"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// TrimmedMeanAlgorithm is an algorithm that drops the lowest and the highest prices before averaging.
+type TrimmedMeanAlgorithm struct {
+	fraction float64
+}
+
+// NewTrimmedMean creates a new initialized instance of TrimmedMeanAlgorithm.
+// fraction is the part of prices dropped from each end, from 0 to 0.5 exclusive.
+func NewTrimmedMean(fraction float64) *TrimmedMeanAlgorithm {
+	checkFraction(fraction)
+
+	return &TrimmedMeanAlgorithm{
+		fraction: fraction,
+	}
+}
+
+// CalculatePrice calculates a trimmed mean price based on prices from different sources.
+func (c *TrimmedMeanAlgorithm) CalculatePrice(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+	if len(prices) == 0 {
+		return decimal.Zero, fmt.Errorf("not enough data to calculate a trimmed mean price")
+	}
+
+	sorted := sortPrices(prices)
+
+	trim := trimCount(len(sorted), c.fraction)
+
+	return mean(sorted[trim : len(sorted)-trim]), nil
+}
+
+// WinsorizedMeanAlgorithm is an algorithm that clamps the lowest and the highest prices before averaging.
+type WinsorizedMeanAlgorithm struct {
+	fraction float64
+}
+
+// NewWinsorizedMean creates a new initialized instance of WinsorizedMeanAlgorithm.
+// fraction is the part of prices clamped at each end, from 0 to 0.5 exclusive.
+func NewWinsorizedMean(fraction float64) *WinsorizedMeanAlgorithm {
+	checkFraction(fraction)
+
+	return &WinsorizedMeanAlgorithm{
+		fraction: fraction,
+	}
+}
+
+// CalculatePrice calculates a winsorized mean price based on prices from different sources.
+// Clamped prices are replaced with the nearest price that is kept.
+func (c *WinsorizedMeanAlgorithm) CalculatePrice(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+	if len(prices) == 0 {
+		return decimal.Zero, fmt.Errorf("not enough data to calculate a winsorized mean price")
+	}
+
+	sorted := sortPrices(prices)
+
+	trim := trimCount(len(sorted), c.fraction)
+
+	for i := 0; i < trim; i++ {
+		sorted[i] = sorted[trim]
+		sorted[len(sorted)-1-i] = sorted[len(sorted)-1-trim]
+	}
+
+	return mean(sorted), nil
+}
+
+func checkFraction(fraction float64) {
+	if fraction < 0 || fraction >= 0.5 {
+		panic(fmt.Sprintf("trimmedalgorithm: invalid fraction %v", fraction))
+	}
+}
+
+// trimCount returns the number of prices dropped or clamped at each end, it is always less than a half.
+func trimCount(n int, fraction float64) int {
+	return int(float64(n) * fraction)
+}
+
+func sortPrices(prices map[types.SourceID]types.SourcePrice) []decimal.Decimal {
+	sorted := make([]decimal.Decimal, 0, len(prices))
+
+	for _, price := range prices {
+		sorted = append(sorted, price.Price)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	return sorted
+}
+
+func mean(values []decimal.Decimal) decimal.Decimal {
+	var sum decimal.Decimal
+
+	for _, value := range values {
+		sum = sum.Add(value)
+	}
+
+	return sum.Div(decimal.NewFromInt(int64(len(values))))
+}
@@ -0,0 +1,88 @@
+package trimmedalgorithm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/trimmedalgorithm"
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+var mockPrices = map[types.SourceID]types.SourcePrice{
+	"a": {Price: decimal.MustParse("1")},
+	"b": {Price: decimal.MustParse("10")},
+	"c": {Price: decimal.MustParse("11")},
+	"d": {Price: decimal.MustParse("15")},
+	"e": {Price: decimal.MustParse("100")},
+}
+
+func TestTrimmedMeanAlgorithm_CalculatePrice(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		algorithm := trimmedalgorithm.NewTrimmedMean(0.2)
+
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		// 1 and 100 are dropped
+		if assert.NoError(t, err) {
+			assert.Equal(t, "12", fairPrice.String())
+		}
+	})
+
+	t.Run("too few sources to trim", func(t *testing.T) {
+		algorithm := trimmedalgorithm.NewTrimmedMean(0.2)
+
+		fairPrice, err := algorithm.CalculatePrice(map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("1")},
+			"b": {Price: decimal.MustParse("2")},
+		})
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "1.5", fairPrice.String())
+		}
+	})
+
+	t.Run("empty prices", func(t *testing.T) {
+		algorithm := trimmedalgorithm.NewTrimmedMean(0.2)
+
+		_, err := algorithm.CalculatePrice(map[types.SourceID]types.SourcePrice{})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid fraction", func(t *testing.T) {
+		assert.Panics(t, func() { trimmedalgorithm.NewTrimmedMean(0.5) })
+	})
+}
+
+func TestWinsorizedMeanAlgorithm_CalculatePrice(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		algorithm := trimmedalgorithm.NewWinsorizedMean(0.2)
+
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		// 1 is clamped to 10 and 100 to 15: (10 + 10 + 11 + 15 + 15) / 5
+		if assert.NoError(t, err) {
+			assert.Equal(t, "12.2", fairPrice.String())
+		}
+	})
+
+	t.Run("no clamping", func(t *testing.T) {
+		algorithm := trimmedalgorithm.NewWinsorizedMean(0)
+
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "27.4", fairPrice.String())
+		}
+	})
+
+	t.Run("empty prices", func(t *testing.T) {
+		algorithm := trimmedalgorithm.NewWinsorizedMean(0.2)
+
+		_, err := algorithm.CalculatePrice(map[types.SourceID]types.SourcePrice{})
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,9 @@
+package types
+
+import "tickerprice/internal/decimal"
+
+// PriceAlgorithm is an algorithm for calculating a fair price based on an array of prices.
+type PriceAlgorithm interface {
+	// CalculatePrice calculates a fair price based on prices from different sources.
+	CalculatePrice(prices map[SourceID]SourcePrice) (decimal.Decimal, error)
+}
@@ -0,0 +1,21 @@
+package types
+
+import "time"
+
+// Candle is an open/high/low/close bar of a timeslot.
+type Candle struct {
+	Open   string // decimal value
+	High   string // decimal value
+	Low    string // decimal value
+	Close  string // decimal value
+	Volume string // decimal value, zero if the sources do not report volume
+}
+
+// TickerCandles is a set of candles of a ticker built for a timeslot.
+type TickerCandles struct {
+	Ticker  Ticker
+	Time    time.Time
+	Fair    Candle              // candle aggregated across sources
+	Sources map[SourceID]Candle // candles of individual sources
+	Status  PriceStatus         // quality of the fair candle
+}
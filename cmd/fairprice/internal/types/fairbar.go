@@ -0,0 +1,35 @@
+package types
+
+import "time"
+
+// FairBar is a fair price of a timeslot with the details of how it was calculated.
+type FairBar struct {
+	Ticker       Ticker
+	Time         time.Time
+	Price        string              // decimal value of the fair price
+	Status       PriceStatus         // quality of the fair price
+	Contributors []SourceID          // sources the fair price is calculated from, in alphabetical order
+	Carried      []SourceID          // contributors with prices carried forward from earlier timeslots
+	Excluded     map[SourceID]string // sources not contributing to the fair price with the reasons
+	Min          string              // decimal value, lowest price of contributors
+	Max          string              // decimal value, highest price of contributors
+	StdDev       string              // decimal value, standard deviation of prices of contributors
+	TickCounts   map[SourceID]int    // number of ticks received from every source in the timeslot
+	Confidence   float64             // from 0 to 1, share of contributing sources reduced by dispersion of prices
+	Bid          string              // decimal value of the fair bid, empty if no contributor reports quotes
+	Ask          string              // decimal value of the fair ask, empty if no contributor reports quotes
+	Mid          string              // decimal value in the middle of the fair bid and ask
+	Spread       string              // decimal value, distance between the fair ask and bid
+}
+
+// TickerPrice returns the fair price for consumers of TickerPrice streams.
+func (b FairBar) TickerPrice() TickerPrice {
+	return TickerPrice{
+		Ticker: b.Ticker,
+		Time:   b.Time,
+		Price:  b.Price,
+		Bid:    b.Bid,
+		Ask:    b.Ask,
+		Status: b.Status,
+	}
+}
@@ -0,0 +1,26 @@
+package types
+
+// PriceStatus describes how reliable a fair price is.
+type PriceStatus int
+
+const (
+	// PriceStatusOK is a fair price calculated from enough sources.
+	PriceStatusOK PriceStatus = iota
+	// PriceStatusStale is the previous fair price carried forward because there was not enough data.
+	PriceStatusStale
+	// PriceStatusLowConfidence is a fair price calculated from fewer sources than required.
+	PriceStatusLowConfidence
+)
+
+func (s PriceStatus) String() string {
+	switch s {
+	case PriceStatusOK:
+		return "ok"
+	case PriceStatusStale:
+		return "stale"
+	case PriceStatusLowConfidence:
+		return "low_confidence"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,19 @@
+package types
+
+import "tickerprice/internal/decimal"
+
+// Quote is a pair of the best bid and ask prices.
+type Quote struct {
+	Bid decimal.Decimal
+	Ask decimal.Decimal
+}
+
+// Mid returns the price in the middle between the bid and the ask.
+func (q Quote) Mid() decimal.Decimal {
+	return q.Bid.Add(q.Ask).Div(decimal.NewFromInt(2))
+}
+
+// Spread returns the distance between the ask and the bid.
+func (q Quote) Spread() decimal.Decimal {
+	return q.Ask.Sub(q.Bid)
+}
@@ -0,0 +1,10 @@
+package types
+
+import "tickerprice/internal/decimal"
+
+// Rejection describes a source price that was not used to calculate the fair price.
+type Rejection struct {
+	Price     decimal.Decimal
+	Deviation float64 // distance from the consensus price, in units defined by the reason
+	Reason    string
+}
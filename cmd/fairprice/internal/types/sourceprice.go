@@ -0,0 +1,27 @@
+package types
+
+import (
+	"time"
+
+	"tickerprice/internal/decimal"
+)
+
+// SourcePrice is a parsed price reported by a single source.
+type SourcePrice struct {
+	Time    time.Time
+	Price   decimal.Decimal
+	Volume  decimal.Decimal // zero if the source does not report volume
+	Bid     decimal.Decimal // zero if the source does not report quotes
+	Ask     decimal.Decimal // zero if the source does not report quotes
+	Carried bool            // the price is carried forward from an earlier timeslot without prices of the source
+}
+
+// Quoted reports whether the source has reported the bid and the ask.
+func (p SourcePrice) Quoted() bool {
+	return !p.Bid.IsZero() && !p.Ask.IsZero()
+}
+
+// Quote returns the bid and the ask of the source.
+func (p SourcePrice) Quote() Quote {
+	return Quote{Bid: p.Bid, Ask: p.Ask}
+}
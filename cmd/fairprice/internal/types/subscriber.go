@@ -2,8 +2,15 @@ package types
 
 import "context"
 
-//go:generate moq -pkg types_test -out mocks_test.go . PriceStreamSubscriber
+//go:generate moq -pkg types_test -out mocks_test.go . PriceStreamSubscriber MultiPriceStreamSubscriber
 
 type PriceStreamSubscriber interface {
 	SubscribePriceStream(context.Context, Ticker) (<-chan TickerPrice, <-chan error)
 }
+
+// MultiPriceStreamSubscriber is a subscriber able to deliver prices of several tickers over a single subscription.
+// Prices of different tickers are distinguished by the Ticker field.
+type MultiPriceStreamSubscriber interface {
+	PriceStreamSubscriber
+	SubscribePriceStreams(context.Context, []Ticker) (<-chan TickerPrice, <-chan error)
+}
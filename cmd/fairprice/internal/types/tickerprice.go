@@ -7,5 +7,9 @@ import (
 type TickerPrice struct {
 	Ticker Ticker
 	Time   time.Time
-	Price  string // decimal value. example: "0", "10", "12.2", "13.2345122"
+	Price  string      // decimal value, may be empty if bid and ask are reported. example: "0", "10", "12.2", "13.2345122"
+	Volume string      // optional decimal value, empty if the source does not report volume. example: "0.5"
+	Bid    string      // optional decimal value of the best bid, reported together with the ask
+	Ask    string      // optional decimal value of the best ask, reported together with the bid
+	Status PriceStatus // quality of a fair price, always PriceStatusOK for prices reported by sources
 }
@@ -2,8 +2,35 @@ package types
 
 import "time"
 
+// Timeslot is the start of a timeslot in seconds since epoch.
 type Timeslot int64
 
+// NewTimeslot returns the timeslot of the specified duration that contains the time.
+// Timeslots are aligned to the Unix epoch, so a one minute timeslot starts at a whole UTC minute.
+func NewTimeslot(t time.Time, duration time.Duration) Timeslot {
+	seconds := int64(duration / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	unix := t.Unix()
+
+	start := unix - unix%seconds
+
+	// round down for times before epoch
+	if unix%seconds < 0 {
+		start -= seconds
+	}
+
+	return Timeslot(start)
+}
+
+// ToTime returns the start time of the timeslot.
 func (t Timeslot) ToTime() time.Time {
 	return time.Unix(int64(t), 0).UTC()
 }
+
+// EndTime returns the end time of the timeslot of the specified duration.
+func (t Timeslot) EndTime(duration time.Duration) time.Time {
+	return t.ToTime().Add(duration)
+}
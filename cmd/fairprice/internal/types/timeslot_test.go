@@ -0,0 +1,34 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/types"
+)
+
+func TestNewTimeslot(t *testing.T) {
+	mockTime := time.Date(2022, 7, 1, 10, 17, 42, 500, time.UTC)
+
+	tests := []struct {
+		duration time.Duration
+		expected time.Time
+	}{
+		{duration: time.Second, expected: time.Date(2022, 7, 1, 10, 17, 42, 0, time.UTC)},
+		{duration: 5 * time.Second, expected: time.Date(2022, 7, 1, 10, 17, 40, 0, time.UTC)},
+		{duration: time.Minute, expected: time.Date(2022, 7, 1, 10, 17, 0, 0, time.UTC)},
+		{duration: 15 * time.Minute, expected: time.Date(2022, 7, 1, 10, 15, 0, 0, time.UTC)},
+		{duration: time.Hour, expected: time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.duration.String(), func(t *testing.T) {
+			timeslot := types.NewTimeslot(mockTime, test.duration)
+
+			assert.Equal(t, test.expected, timeslot.ToTime())
+			assert.Equal(t, test.expected.Add(test.duration), timeslot.EndTime(test.duration))
+		})
+	}
+}
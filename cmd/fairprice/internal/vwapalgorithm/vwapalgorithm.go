@@ -0,0 +1,45 @@
+package vwapalgorithm
+
+import (
+	"fmt"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// VWAPAlgorithm is an algorithm that weights the price of each source by its traded volume.
+type VWAPAlgorithm struct{}
+
+// New creates a new initialized instance of VWAPAlgorithm.
+func New() *VWAPAlgorithm {
+	return &VWAPAlgorithm{}
+}
+
+// CalculatePrice calculates a volume-weighted average price based on prices from different sources.
+// Sources without volume do not contribute to the price.
+func (c *VWAPAlgorithm) CalculatePrice(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+	var (
+		weightedSum decimal.Decimal
+		totalVolume decimal.Decimal
+	)
+
+	for _, price := range prices {
+		if price.Volume.Sign() <= 0 {
+			continue
+		}
+
+		weightedSum = weightedSum.Add(price.Price.Mul(price.Volume))
+		totalVolume = totalVolume.Add(price.Volume)
+	}
+
+	if totalVolume.IsZero() {
+		return decimal.Zero, fmt.Errorf("not enough data to calculate a volume-weighted average price")
+	}
+
+	return weightedSum.Div(totalVolume), nil
+}
+
+// PriceWeight returns the volume of the source as its weight in the fair price.
+func (c *VWAPAlgorithm) PriceWeight(_ types.SourceID, price types.SourcePrice) float64 {
+	return price.Volume.Float64()
+}
@@ -0,0 +1,43 @@
+package vwapalgorithm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/cmd/fairprice/internal/vwapalgorithm"
+	"tickerprice/internal/decimal"
+)
+
+func TestVWAPAlgorithm_CalculatePrice(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("100.0"), Volume: decimal.MustParse("9.0")},
+			"b": {Price: decimal.MustParse("110.0"), Volume: decimal.MustParse("1.0")},
+			"c": {Price: decimal.MustParse("500.0")},
+		}
+
+		expectedPrice := "101"
+
+		algorithm := vwapalgorithm.New()
+
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, expectedPrice, fairPrice.String())
+		}
+	})
+
+	t.Run("no volume", func(t *testing.T) {
+		mockPrices := map[types.SourceID]types.SourcePrice{
+			"a": {Price: decimal.MustParse("100.0")},
+		}
+
+		algorithm := vwapalgorithm.New()
+
+		_, err := algorithm.CalculatePrice(mockPrices)
+
+		assert.Error(t, err)
+	})
+}
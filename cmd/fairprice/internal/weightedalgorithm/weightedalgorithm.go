@@ -0,0 +1,154 @@
+package weightedalgorithm
+
+import (
+	"fmt"
+	"sync"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
+)
+
+// WeightedAlgorithm is an algorithm that weights the price of each source by the trust in the source.
+// Weights can be changed at any time, the next calculated price uses the new weights.
+type WeightedAlgorithm struct {
+	mutex         sync.RWMutex
+	weights       map[types.SourceID]decimal.Decimal
+	defaultWeight decimal.Decimal
+}
+
+// New creates a new initialized instance of WeightedAlgorithm.
+// Sources missing in the weights use the default weight. Weights must not be negative.
+func New(defaultWeight decimal.Decimal, weights map[types.SourceID]decimal.Decimal) *WeightedAlgorithm {
+	a := &WeightedAlgorithm{}
+
+	if err := a.SetDefaultWeight(defaultWeight); err != nil {
+		panic(fmt.Sprintf("weightedalgorithm: %v", err))
+	}
+
+	if err := a.SetWeights(weights); err != nil {
+		panic(fmt.Sprintf("weightedalgorithm: %v", err))
+	}
+
+	return a
+}
+
+// CalculatePrice calculates a weighted average price based on prices from different sources.
+// Sources with zero weight do not contribute to the price.
+// The price is calculated with the weights set before the call, even if they are changed during the call.
+func (a *WeightedAlgorithm) CalculatePrice(prices map[types.SourceID]types.SourcePrice) (decimal.Decimal, error) {
+	weights := a.sourceWeights(prices)
+
+	var (
+		weightedSum decimal.Decimal
+		totalWeight decimal.Decimal
+	)
+
+	for sourceID, price := range prices {
+		weight := weights[sourceID]
+
+		weightedSum = weightedSum.Add(price.Price.Mul(weight))
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	if totalWeight.IsZero() {
+		return decimal.Zero, fmt.Errorf("not enough data to calculate a weighted average price")
+	}
+
+	return weightedSum.Div(totalWeight), nil
+}
+
+// PriceWeight returns the configured weight of the source as its weight in the fair price.
+func (a *WeightedAlgorithm) PriceWeight(sourceID types.SourceID, _ types.SourcePrice) float64 {
+	return a.weight(sourceID).Float64()
+}
+
+// SetWeight sets the weight of the source.
+func (a *WeightedAlgorithm) SetWeight(sourceID types.SourceID, weight decimal.Decimal) error {
+	if weight.Sign() < 0 {
+		return fmt.Errorf("negative weight %v of source %s", weight, sourceID)
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.weights[sourceID] = weight
+
+	return nil
+}
+
+// SetWeights replaces weights of all sources.
+func (a *WeightedAlgorithm) SetWeights(weights map[types.SourceID]decimal.Decimal) error {
+	copied := make(map[types.SourceID]decimal.Decimal, len(weights))
+
+	for sourceID, weight := range weights {
+		if weight.Sign() < 0 {
+			return fmt.Errorf("negative weight %v of source %s", weight, sourceID)
+		}
+
+		copied[sourceID] = weight
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.weights = copied
+
+	return nil
+}
+
+// SetDefaultWeight sets the weight of sources missing in the weights.
+func (a *WeightedAlgorithm) SetDefaultWeight(weight decimal.Decimal) error {
+	if weight.Sign() < 0 {
+		return fmt.Errorf("negative default weight %v", weight)
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.defaultWeight = weight
+
+	return nil
+}
+
+// Weights returns a copy of the weights of sources.
+func (a *WeightedAlgorithm) Weights() map[types.SourceID]decimal.Decimal {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	weights := make(map[types.SourceID]decimal.Decimal, len(a.weights))
+
+	for sourceID, weight := range a.weights {
+		weights[sourceID] = weight
+	}
+
+	return weights
+}
+
+func (a *WeightedAlgorithm) weight(sourceID types.SourceID) decimal.Decimal {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if weight, ok := a.weights[sourceID]; ok {
+		return weight
+	}
+
+	return a.defaultWeight
+}
+
+// sourceWeights returns weights of sources of the prices taken at once.
+func (a *WeightedAlgorithm) sourceWeights(prices map[types.SourceID]types.SourcePrice) map[types.SourceID]decimal.Decimal {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	weights := make(map[types.SourceID]decimal.Decimal, len(prices))
+
+	for sourceID := range prices {
+		if weight, ok := a.weights[sourceID]; ok {
+			weights[sourceID] = weight
+		} else {
+			weights[sourceID] = a.defaultWeight
+		}
+	}
+
+	return weights
+}
@@ -0,0 +1,98 @@
+package weightedalgorithm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/cmd/fairprice/internal/weightedalgorithm"
+	"tickerprice/internal/decimal"
+)
+
+func TestWeightedAlgorithm_CalculatePrice(t *testing.T) {
+	mockPrices := map[types.SourceID]types.SourcePrice{
+		"a": {Price: decimal.MustParse("100")},
+		"b": {Price: decimal.MustParse("110")},
+		"c": {Price: decimal.MustParse("500")},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		algorithm := weightedalgorithm.New(decimal.NewFromInt(1), map[types.SourceID]decimal.Decimal{
+			"a": decimal.NewFromInt(3),
+			"c": decimal.Zero,
+		})
+
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		// (100 * 3 + 110 * 1) / 4
+		if assert.NoError(t, err) {
+			assert.Equal(t, "102.5", fairPrice.String())
+		}
+
+		assert.Equal(t, 3.0, algorithm.PriceWeight("a", mockPrices["a"]))
+		assert.Equal(t, 1.0, algorithm.PriceWeight("b", mockPrices["b"]))
+	})
+
+	t.Run("change weights", func(t *testing.T) {
+		algorithm := weightedalgorithm.New(decimal.NewFromInt(1), nil)
+
+		assert.NoError(t, algorithm.SetWeight("c", decimal.Zero))
+		assert.NoError(t, algorithm.SetDefaultWeight(decimal.NewFromInt(2)))
+		assert.Error(t, algorithm.SetWeight("a", decimal.NewFromInt(-1)))
+
+		fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "105", fairPrice.String())
+		}
+
+		assert.NoError(t, algorithm.SetWeights(map[types.SourceID]decimal.Decimal{"b": decimal.Zero}))
+		assert.Equal(t, map[types.SourceID]decimal.Decimal{"b": decimal.Zero}, algorithm.Weights())
+	})
+
+	t.Run("concurrent weights", func(t *testing.T) {
+		algorithm := weightedalgorithm.New(decimal.Zero, map[types.SourceID]decimal.Decimal{"a": decimal.NewFromInt(1)})
+
+		done := make(chan struct{})
+		defer close(done)
+
+		// the weight is moved between the sources, the price is never calculated from a mix of both weights
+		go func() {
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				sourceID := types.SourceID("a")
+				if i%2 == 1 {
+					sourceID = "b"
+				}
+
+				_ = algorithm.SetWeights(map[types.SourceID]decimal.Decimal{sourceID: decimal.NewFromInt(1)})
+			}
+		}()
+
+		for i := 0; i < 100000; i++ {
+			fairPrice, err := algorithm.CalculatePrice(mockPrices)
+
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if !assert.Contains(t, []string{"100", "110"}, fairPrice.String()) {
+				return
+			}
+		}
+	})
+
+	t.Run("zero weights", func(t *testing.T) {
+		algorithm := weightedalgorithm.New(decimal.Zero, nil)
+
+		_, err := algorithm.CalculatePrice(mockPrices)
+
+		assert.Error(t, err)
+	})
+}
@@ -2,20 +2,33 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"time"
 
-	"tickerprice/cmd/fairprice/internal/averagealgorithm"
+	"tickerprice/cmd/fairprice/internal/algorithmregistry"
 	"tickerprice/cmd/fairprice/internal/fairpricesource"
 	"tickerprice/cmd/fairprice/internal/memstorage"
 	"tickerprice/cmd/fairprice/internal/mockpricesource"
+	"tickerprice/cmd/fairprice/internal/pricebroker"
 	"tickerprice/cmd/fairprice/internal/priceprinter"
+	"tickerprice/cmd/fairprice/internal/tickerregistry"
 	"tickerprice/cmd/fairprice/internal/types"
+	"tickerprice/internal/decimal"
 	"tickerprice/internal/log"
 )
 
 func main() {
+	var algorithmConfig algorithmregistry.Config
+
+	flag.StringVar(&algorithmConfig.Name, "algorithm", "average",
+		fmt.Sprintf("fair price algorithm, one of %v", algorithmregistry.Names()))
+	flag.Float64Var(&algorithmConfig.TrimFraction, "trim", 0.2,
+		"part of prices trimmed at each end by trimmed_mean and winsorized_mean")
+	flag.Parse()
+
 	ctx, done := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer done()
 
@@ -31,19 +44,59 @@ func main() {
 		"source_c": priceSourceC,
 	}
 
-	algorithm := averagealgorithm.New()
+	algorithm, err := algorithmregistry.New(algorithmConfig)
+	if err != nil {
+		log.Errorf(ctx, "create algorithm: %v", err)
+		return
+	}
+
+	storage := memstorage.New(memstorage.WithRetention(memstorage.RetentionPolicy{
+		MaxAge:           10 * time.Minute,
+		TimeslotDuration: time.Minute,
+	}))
+
+	go storage.RunSweeper(ctx, time.Minute)
+
+	registry := tickerregistry.New()
 
-	storage := memstorage.New()
+	err = registry.Register(tickerregistry.TickerInfo{
+		Ticker:    types.BTCUSDTicker,
+		Base:      "BTC",
+		Quote:     "USD",
+		Precision: 2,
+		TickSize:  decimal.MustParse("0.01"),
+	})
+	if err != nil {
+		log.Errorf(ctx, "register ticker: %v", err)
+		return
+	}
+
+	fairPriceSource := fairpricesource.New(
+		algorithm,
+		storage,
+		subscribers,
+		time.Minute,
+		time.Now,
+		fairpricesource.WithTickerRegistry(registry),
+	)
 
-	fairPriceSource := fairpricesource.New(algorithm, storage, subscribers, time.Now)
+	broker := pricebroker.New(fairPriceSource)
 
 	printer := priceprinter.New()
 
-	tickers, errs := fairPriceSource.SubscribePriceStream(ctx, types.BTCUSDTicker)
+	tickers, errs := broker.SubscribePriceStream(ctx, types.BTCUSDTicker)
+
+	errsDone := make(chan struct{})
+
+	go func() {
+		defer close(errsDone)
+
+		for err := range errs {
+			log.Errorf(ctx, "fair price subscription: %v", err)
+		}
+	}()
 
 	printer.Print(tickers)
 
-	for err := range errs {
-		log.Errorf(ctx, "fair price subscription: %v", err)
-	}
+	<-errsDone
 }
@@ -0,0 +1,273 @@
+package decimal
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DivisionPrecision is the number of fractional digits used to print results of divisions
+// which cannot be represented as a finite decimal.
+const DivisionPrecision = 20
+
+// Decimal is an immutable exact decimal number. The zero value is zero.
+type Decimal struct {
+	rat *big.Rat
+}
+
+// Zero is the zero decimal.
+var Zero = Decimal{}
+
+// Parse parses a decimal value. example: "0", "-10", "12.2", "13.2345122".
+// Only plain notation is accepted, exponents, hexadecimal numbers and fractions are not.
+func Parse(s string) (Decimal, error) {
+	if !isPlain(s) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	return fromRat(r), nil
+}
+
+// isPlain reports whether the string is an optionally negative number of digits with an optional fraction.
+func isPlain(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+
+	integer, fraction, hasFraction := strings.Cut(s, ".")
+
+	return isDigits(integer) && (!hasFraction || isDigits(fraction))
+}
+
+// isDigits reports whether the string is a non-empty sequence of decimal digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MustParse parses a decimal value and panics if it is invalid.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// NewFromInt creates a decimal from an integer.
+func NewFromInt(i int64) Decimal {
+	return fromRat(new(big.Rat).SetInt64(i))
+}
+
+// NewFromFloat creates a decimal from the shortest decimal representation of a float.
+func NewFromFloat(f float64) Decimal {
+	return MustParse(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Add returns d + e.
+func (d Decimal) Add(e Decimal) Decimal {
+	return fromRat(new(big.Rat).Add(d.value(), e.value()))
+}
+
+// Sub returns d - e.
+func (d Decimal) Sub(e Decimal) Decimal {
+	return fromRat(new(big.Rat).Sub(d.value(), e.value()))
+}
+
+// Mul returns d * e.
+func (d Decimal) Mul(e Decimal) Decimal {
+	return fromRat(new(big.Rat).Mul(d.value(), e.value()))
+}
+
+// Div returns d / e. It panics if e is zero.
+func (d Decimal) Div(e Decimal) Decimal {
+	if e.IsZero() {
+		panic("decimal: division by zero")
+	}
+
+	return fromRat(new(big.Rat).Quo(d.value(), e.value()))
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return fromRat(new(big.Rat).Neg(d.value()))
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	return fromRat(new(big.Rat).Abs(d.value()))
+}
+
+// Cmp compares d and e and returns -1, 0 or +1.
+func (d Decimal) Cmp(e Decimal) int {
+	return d.value().Cmp(e.value())
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+// Equal reports whether d and e are the same number.
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// IsZero reports whether d is zero.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Float64 returns the nearest float value.
+func (d Decimal) Float64() float64 {
+	f, _ := d.value().Float64()
+
+	return f
+}
+
+// Round rounds d to the specified number of fractional digits using the rounding mode.
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	scale := pow10(places)
+
+	scaled := new(big.Rat).Mul(d.value(), scale)
+
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	if remainder.Sign() != 0 && mode.roundsAway(quotient, remainder, scaled.Denom(), scaled.Sign()) {
+		if scaled.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return fromRat(new(big.Rat).Quo(new(big.Rat).SetInt(quotient), scale))
+}
+
+// RoundStep rounds d to a multiple of the step using the rounding mode. A zero step leaves d unchanged.
+func (d Decimal) RoundStep(step Decimal, mode RoundingMode) Decimal {
+	if step.IsZero() {
+		return d
+	}
+
+	return d.Div(step).Round(0, mode).Mul(step)
+}
+
+// String returns the exact decimal representation without trailing zeros.
+// Values which cannot be represented as a finite decimal are printed with DivisionPrecision digits.
+func (d Decimal) String() string {
+	places, ok := finitePlaces(d.value().Denom())
+	if !ok || places > DivisionPrecision {
+		places = DivisionPrecision
+	}
+
+	s := d.value().FloatString(int(places))
+
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+
+	if s == "-0" {
+		return "0"
+	}
+
+	return s
+}
+
+// StringFixed returns the decimal representation with exactly the specified number of fractional digits.
+// The value is rounded half away from zero if it has more digits.
+func (d Decimal) StringFixed(places int32) string {
+	if places < 0 {
+		places = 0
+	}
+
+	return d.Round(places, RoundHalfUp).value().FloatString(int(places))
+}
+
+// fromRat creates a decimal from the rational number, zero is always stored as the zero value.
+func fromRat(r *big.Rat) Decimal {
+	if r.Sign() == 0 {
+		return Decimal{}
+	}
+
+	return Decimal{rat: r}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+
+	return nil
+}
+
+func (d Decimal) value() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+
+	return d.rat
+}
+
+// finitePlaces returns the number of fractional digits of a decimal with the denominator
+// or false if the decimal is infinite.
+func finitePlaces(denominator *big.Int) (int32, bool) {
+	var (
+		n      = new(big.Int).Set(denominator)
+		twos   int32
+		fives  int32
+		two    = big.NewInt(2)
+		five   = big.NewInt(5)
+		modulo = new(big.Int)
+	)
+
+	for n.Cmp(big.NewInt(1)) > 0 {
+		switch {
+		case modulo.Mod(n, two).Sign() == 0:
+			n.Quo(n, two)
+			twos++
+		case modulo.Mod(n, five).Sign() == 0:
+			n.Quo(n, five)
+			fives++
+		default:
+			return 0, false
+		}
+	}
+
+	if twos > fives {
+		return twos, true
+	}
+
+	return fives, true
+}
+
+func pow10(places int32) *big.Rat {
+	if places < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-places)), nil))
+	}
+
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil))
+}
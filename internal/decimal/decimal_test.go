@@ -0,0 +1,109 @@
+package decimal_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tickerprice/internal/decimal"
+)
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"0", "-10", "12.2", "13.2345122", "60000.123456789012345678"} {
+		d, err := decimal.Parse(s)
+
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, s, d.String())
+		}
+	}
+
+	for _, s := range []string{"", "abc", "1/3", "1.2.3", "-", "1.", ".5", "+1", "1e-3", "1e100000", "0x10", "0b1", " 1"} {
+		_, err := decimal.Parse(s)
+
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a := decimal.MustParse("1.1")
+	b := decimal.MustParse("2.2")
+
+	assert.Equal(t, "3.3", a.Add(b).String())
+	assert.Equal(t, "-1.1", a.Sub(b).String())
+	assert.Equal(t, "2.42", a.Mul(b).String())
+	assert.Equal(t, "0.5", a.Div(b).String())
+	assert.Equal(t, "0.33333333333333333333", decimal.NewFromInt(1).Div(decimal.NewFromInt(3)).String())
+	assert.Equal(t, "0", decimal.Zero.String())
+	assert.Equal(t, "0.1", decimal.NewFromFloat(0.1).String())
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.Equal(t, 0, a.Cmp(decimal.MustParse("1.10")))
+	assert.True(t, a.Equal(decimal.MustParse("1.10")))
+	assert.Panics(t, func() { a.Div(decimal.Zero) })
+}
+
+func TestDecimal_Round(t *testing.T) {
+	tests := []struct {
+		value string
+		mode  decimal.RoundingMode
+		want  string
+	}{
+		{"1.25", decimal.RoundHalfUp, "1.3"},
+		{"-1.25", decimal.RoundHalfUp, "-1.3"},
+		{"1.25", decimal.RoundHalfEven, "1.2"},
+		{"1.35", decimal.RoundHalfEven, "1.4"},
+		{"-1.25", decimal.RoundHalfEven, "-1.2"},
+		{"1.29", decimal.RoundDown, "1.2"},
+		{"-1.29", decimal.RoundDown, "-1.2"},
+		{"1.21", decimal.RoundUp, "1.3"},
+		{"-1.21", decimal.RoundUp, "-1.3"},
+		{"-1.21", decimal.RoundFloor, "-1.3"},
+		{"1.29", decimal.RoundFloor, "1.2"},
+		{"1.21", decimal.RoundCeiling, "1.3"},
+		{"-1.29", decimal.RoundCeiling, "-1.2"},
+		{"1.2", decimal.RoundUp, "1.2"},
+	}
+
+	for _, tt := range tests {
+		got := decimal.MustParse(tt.value).Round(1, tt.mode)
+
+		assert.Equal(t, tt.want, got.String(), "%s %s", tt.value, tt.mode)
+	}
+
+	assert.Equal(t, "1200", decimal.MustParse("1234").Round(-2, decimal.RoundHalfUp).String())
+}
+
+func TestDecimal_StringFixed(t *testing.T) {
+	assert.Equal(t, "1.10", decimal.MustParse("1.1").StringFixed(2))
+	assert.Equal(t, "1.13", decimal.MustParse("1.125").StringFixed(2))
+	assert.Equal(t, "2", decimal.MustParse("1.5").StringFixed(0))
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	mode, err := decimal.ParseRoundingMode("half_even")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, decimal.RoundHalfEven, mode)
+	}
+
+	_, err = decimal.ParseRoundingMode("nearest")
+
+	assert.Error(t, err)
+}
+
+func TestDecimal_RoundStep(t *testing.T) {
+	step := decimal.MustParse("0.05")
+
+	assert.Equal(t, "1.25", decimal.MustParse("1.26").RoundStep(step, decimal.RoundHalfUp).String())
+	assert.Equal(t, "1.3", decimal.MustParse("1.26").RoundStep(step, decimal.RoundCeiling).String())
+	assert.Equal(t, "1.26", decimal.MustParse("1.26").RoundStep(decimal.Zero, decimal.RoundCeiling).String())
+}
+
+func TestDecimal_UnmarshalText(t *testing.T) {
+	var d decimal.Decimal
+
+	if assert.NoError(t, d.UnmarshalText([]byte("0.01"))) {
+		assert.Equal(t, "0.01", d.String())
+	}
+
+	assert.Error(t, d.UnmarshalText([]byte("cent")))
+}
@@ -0,0 +1,77 @@
+package decimal
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode defines how a value is rounded to a number of fractional digits.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest value, ties away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest value, ties to the even digit.
+	RoundHalfEven
+	// RoundDown rounds towards zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+	// RoundCeiling rounds towards positive infinity.
+	RoundCeiling
+)
+
+// ParseRoundingMode parses the name of a rounding mode.
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	for mode, name := range roundingModeNames {
+		if name == s {
+			return mode, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown rounding mode %q", s)
+}
+
+var roundingModeNames = map[RoundingMode]string{
+	RoundHalfUp:   "half_up",
+	RoundHalfEven: "half_even",
+	RoundDown:     "down",
+	RoundUp:       "up",
+	RoundFloor:    "floor",
+	RoundCeiling:  "ceiling",
+}
+
+func (m RoundingMode) String() string {
+	if name, ok := roundingModeNames[m]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// roundsAway reports whether the truncated quotient must be moved one unit away from zero.
+func (m RoundingMode) roundsAway(quotient, remainder, denominator *big.Int, sign int) bool {
+	// compare the doubled remainder with the denominator to find out the position relative to the half
+	half := new(big.Int).Abs(remainder)
+	half.Lsh(half, 1)
+	half.Sub(half, denominator)
+
+	switch m {
+	case RoundHalfUp:
+		return half.Sign() >= 0
+	case RoundHalfEven:
+		return half.Sign() > 0 || (half.Sign() == 0 && quotient.Bit(0) == 1)
+	case RoundDown:
+		return false
+	case RoundUp:
+		return true
+	case RoundFloor:
+		return sign < 0
+	case RoundCeiling:
+		return sign > 0
+	default:
+		return half.Sign() >= 0
+	}
+}